@@ -2,9 +2,12 @@ package jsonrpc2
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 )
 
 //--------------------------------------------------------------------------------//
@@ -15,6 +18,11 @@ type ClientTransport interface {
 	Execute(RequestSlice) (ResponseSlice, error)
 }
 
+type ClientTransportContext interface {
+	ClientTransport
+	ExecuteContext(context.Context, RequestSlice) (ResponseSlice, error)
+}
+
 type ClientTransportHttp struct {
 	ClientTransport
 
@@ -22,25 +30,39 @@ type ClientTransportHttp struct {
 }
 
 func (clientTransport *ClientTransportHttp) Execute(requestSlice RequestSlice) (responseSlice ResponseSlice, err error) {
+	return clientTransport.ExecuteContext(context.Background(), requestSlice)
+}
+
+func (clientTransport *ClientTransportHttp) ExecuteContext(ctx context.Context, requestSlice RequestSlice) (responseSlice ResponseSlice, err error) {
 	var (
 		requestSliceJson []byte
 
 		requestSliceBuffer *bytes.Buffer
+		httpRequest        *http.Request
 		httpResponse       *http.Response
 	)
 
-	requestSliceJson, err = requestSlice.MarshalJSON()
+	requestSliceJson, err = RequestMessage{RequestSlice: requestSlice, Batch: requestBatchFromContext(ctx, requestSlice)}.MarshalJSON()
 	if err != nil {
 		return
 	}
 
 	requestSliceBuffer = bytes.NewBuffer(requestSliceJson)
 
-	httpResponse, err = http.Post(clientTransport.endpoint, "application/json", requestSliceBuffer)
+	httpRequest, err = http.NewRequest("POST", clientTransport.endpoint, requestSliceBuffer)
+	if err != nil {
+		return
+	}
+
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err = http.DefaultClient.Do(httpRequest.WithContext(ctx))
 	if err != nil {
 		return
 	}
 
+	defer httpResponse.Body.Close()
+
 	responseSlice, err = NewResponseSlice(httpResponse.Body)
 	if err != nil {
 		return
@@ -63,7 +85,13 @@ type clientExecuteUnit struct {
 	controlMutex chan interface{}
 	executeMutex chan interface{}
 
+	context     context.Context
+	cancelError *Error
+	cancelFunc  func()
+	doneFunc    func()
+
 	index int64
+	id    interface{}
 
 	method string
 	option json.RawMessage
@@ -76,8 +104,18 @@ func (executeUnit *clientExecuteUnit) Wait() {
 	executeUnit.controlMutex <- true
 
 	if executeUnit.executeMutex != nil {
-		<-executeUnit.executeMutex
-		executeUnit.executeMutex = nil
+		select {
+		case <-executeUnit.executeMutex:
+			executeUnit.executeMutex = nil
+			executeUnit.cancelError = nil
+		case <-executeUnit.context.Done():
+			executeUnit.cancelError = NewErrorRequestCancelled(executeUnit.context.Err().Error())
+		}
+
+		if executeUnit.doneFunc != nil {
+			executeUnit.doneFunc()
+			executeUnit.doneFunc = nil
+		}
 	}
 
 	<-executeUnit.controlMutex
@@ -86,6 +124,10 @@ func (executeUnit *clientExecuteUnit) Wait() {
 func (executeUnit *clientExecuteUnit) Response(result interface{}) *Error {
 	executeUnit.Wait()
 
+	if executeUnit.cancelError != nil {
+		return executeUnit.cancelError
+	}
+
 	if result != nil && executeUnit.result != nil {
 		err := json.Unmarshal(executeUnit.result, result)
 		if err != nil {
@@ -96,6 +138,60 @@ func (executeUnit *clientExecuteUnit) Response(result interface{}) *Error {
 	return executeUnit.error
 }
 
+type clientBatchContext struct {
+	context.Context
+
+	done chan struct{}
+	stop chan struct{}
+}
+
+func newClientBatchContext(callArray []*clientCallUnit) (context.Context, func()) {
+	var batchContext *clientBatchContext
+
+	if len(callArray) == 1 {
+		return callArray[0].executeUnit.context, func() {}
+	}
+
+	batchContext = &clientBatchContext{
+		Context: callArray[0].executeUnit.context,
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+
+	go func() {
+		for _, callUnit := range callArray {
+			select {
+			case <-callUnit.executeUnit.context.Done():
+			case <-batchContext.stop:
+				return
+			}
+		}
+
+		close(batchContext.done)
+	}()
+
+	return batchContext, func() {
+		close(batchContext.stop)
+	}
+}
+
+func (batchContext *clientBatchContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (batchContext *clientBatchContext) Done() <-chan struct{} {
+	return batchContext.done
+}
+
+func (batchContext *clientBatchContext) Err() error {
+	select {
+	case <-batchContext.done:
+		return context.Canceled
+	default:
+		return nil
+	}
+}
+
 type clientExecuteRequest interface {
 	Wait()
 	Response(interface{}) *Error
@@ -109,109 +205,305 @@ type clientExecuteNotification interface {
 // CLIENT
 //--------------------------------------------------------------------------------//
 
+type clientCallUnit struct {
+	executeUnit *clientExecuteUnit
+
+	arriveMutex  chan interface{}
+	responseDone chan interface{}
+	submitted    bool
+
+	requestUnit  *RequestUnit
+	responseUnit *ResponseUnit
+	err          error
+}
+
+func (callUnit *clientCallUnit) arrive() bool {
+	select {
+	case callUnit.arriveMutex <- true:
+		return true
+	default:
+		return false
+	}
+}
+
+type ClientBatchMode int
+
+const (
+	ClientBatchAuto ClientBatchMode = iota
+	ClientBatchAlways
+	ClientBatchNever
+)
+
 type Client struct {
 	mutex     chan interface{}
 	transport ClientTransport
 
+	batchMode ClientBatchMode
+
 	executeIndex int64
 	executeArray []*clientExecuteUnit
+
+	idGenerator ClientIDGenerator
+
+	transportInterceptorArray []ClientTransportInterceptor
+	callInterceptorArray      []ClientCallInterceptor
+
+	cancelMethod string
+
+	progressMethod     string
+	progressTokenField string
+}
+
+func (client *Client) SetBatchMode(batchMode ClientBatchMode) {
+	client.mutex <- true
+	client.batchMode = batchMode
+	<-client.mutex
+}
+
+func (client *Client) SetCancelMethod(cancelMethod string) {
+	client.mutex <- true
+	client.cancelMethod = cancelMethod
+	<-client.mutex
+}
+
+func (client *Client) cancel(executeID interface{}) func() {
+	notifier, ok := client.transport.(interface {
+		Notify(string, interface{}) error
+	})
+
+	if !ok || client.cancelMethod == "" {
+		return nil
+	}
+
+	cancelMethod := client.cancelMethod
+
+	return func() {
+		notifier.Notify(cancelMethod, map[string]interface{}{"id": executeID})
+	}
 }
 
 func (client *Client) execute() {
 	var (
-		executeIndex  int64
-		executeMap    = map[int64]*clientExecuteUnit{}
-		executeUnit   *clientExecuteUnit
-		requestUnit   *RequestUnit
-		requestSlice  = RequestSlice{}
-		responseUnit  *ResponseUnit
-		responseSlice ResponseSlice
-		err           error
+		executeArray     []*clientExecuteUnit
+		executeUnit      *clientExecuteUnit
+		callArray        []*clientCallUnit
+		callUnit         *clientCallUnit
+		callChan         chan *clientCallUnit
+		transportFunc    ClientTransportFunc
+		interceptorArray []ClientCallInterceptor
+		batchMode        ClientBatchMode
+		transportWaiting sync.WaitGroup
 	)
 
 	client.mutex <- true
-	defer func() {
-		<-client.mutex
-	}()
 
-	if len(client.executeArray) == 0 {
+	executeArray = client.executeArray
+	client.executeArray = []*clientExecuteUnit{}
+
+	transportFunc = client.transportChain(client.transportInterceptorArray)
+	interceptorArray = client.callInterceptorArray
+	batchMode = client.batchMode
+
+	<-client.mutex
+
+	if len(executeArray) == 0 {
 		return
 	}
 
-	for _, executeUnit = range client.executeArray {
-		requestUnit = &RequestUnit{
-			JsonRPC: "2.0",
-			Method:  executeUnit.method,
-			Params:  executeUnit.option,
+	callChan = make(chan *clientCallUnit, len(executeArray))
+
+	for _, executeUnit = range executeArray {
+		callUnit = &clientCallUnit{
+			executeUnit:  executeUnit,
+			arriveMutex:  make(chan interface{}, 1),
+			responseDone: make(chan interface{}),
+			requestUnit: &RequestUnit{
+				JsonRPC: "2.0",
+				Method:  executeUnit.method,
+				Params:  executeUnit.option,
+			},
 		}
 
-		if executeUnit.index >= 0 {
-			requestUnit.ID = executeUnit.index
+		if executeUnit.id != nil {
+			callUnit.requestUnit.ID = executeUnit.id
 		}
 
-		executeMap[executeUnit.index] = executeUnit
-		requestSlice = append(requestSlice, requestUnit)
+		callArray = append(callArray, callUnit)
+
+		go client.executeCall(callUnit, callChan, interceptorArray)
 	}
 
-	client.executeArray = []*clientExecuteUnit{}
+	callArray = callArray[:0]
 
-	responseSlice, err = client.transport.Execute(requestSlice)
+	for range executeArray {
+		callUnit = <-callChan
+		if callUnit.submitted {
+			callArray = append(callArray, callUnit)
+		}
+	}
 
-	if err != nil {
-		for _, executeUnit = range executeMap {
-			executeUnit.error = NewErrorInternalError(err.Error())
-			executeUnit.executeMutex <- true
+	switch {
+	case len(callArray) == 0:
+	case batchMode == ClientBatchNever:
+		for _, callUnit = range callArray {
+			transportWaiting.Add(1)
+			go func(callUnit *clientCallUnit) {
+				defer transportWaiting.Done()
+				client.executeTransport(WithRequestBatch(callUnit.executeUnit.context, false), transportFunc, []*clientCallUnit{callUnit})
+			}(callUnit)
 		}
 
-		return
+		transportWaiting.Wait()
+	default:
+		// the batch is aborted only once every call in it has been abandoned
+		ctx, stopFunc := newClientBatchContext(callArray)
+		defer stopFunc()
+
+		if batchMode == ClientBatchAlways {
+			ctx = WithRequestBatch(ctx, true)
+		}
+
+		client.executeTransport(ctx, transportFunc, callArray)
 	}
+}
 
-	for _, responseUnit = range responseSlice {
-		if responseUnit.ID != nil {
-			executeIndexFloat, ok := responseUnit.ID.(float64)
-			if ok {
-				executeIndex = int64(executeIndexFloat)
-			}
+func (client *Client) executeTransport(ctx context.Context, transportFunc ClientTransportFunc, callArray []*clientCallUnit) {
+	var (
+		callUnit      *clientCallUnit
+		callMap       = map[string]*clientCallUnit{}
+		requestSlice  = RequestSlice{}
+		responseUnit  *ResponseUnit
+		responseSlice ResponseSlice
+		err           error
+	)
 
-			executeUnit := executeMap[executeIndex]
-			if executeUnit != nil {
-				executeUnit.result = responseUnit.Result
-				executeUnit.error = responseUnit.Error
+	for _, callUnit = range callArray {
+		requestSlice = append(requestSlice, callUnit.requestUnit)
 
-				delete(executeMap, executeIndex)
-				executeUnit.executeMutex <- true
-			} else {
-				fmt.Println("client.execute", "unknown executeUnit", responseUnit)
+		if callUnit.requestUnit.ID != nil {
+			callMap[idKey(callUnit.requestUnit.ID)] = callUnit
+		}
+	}
+
+	responseSlice, err = transportFunc(ctx, requestSlice)
+
+	if err == nil {
+		for _, responseUnit = range responseSlice {
+			if responseUnit.ID == nil {
+				continue
+			}
+
+			callUnit = callMap[idKey(responseUnit.ID)]
+			if callUnit != nil && callUnit.responseUnit == nil {
+				callUnit.responseUnit = responseUnit
 			}
-		} else {
-			fmt.Println("client.execute", "unknown response unit", responseUnit)
 		}
 	}
 
-	for _, executeUnit = range executeMap {
-		if executeUnit.index >= 0 {
-			executeUnit.error = NewErrorInternalError(nil)
+	for _, callUnit = range callArray {
+		callUnit.err = err
+		close(callUnit.responseDone)
+	}
+}
+
+func (client *Client) executeCall(callUnit *clientCallUnit, callChan chan *clientCallUnit, interceptorArray []ClientCallInterceptor) {
+	var (
+		executeUnit  = callUnit.executeUnit
+		callFunc     ClientCallFunc
+		responseUnit *ResponseUnit
+		watchMutex   = make(chan interface{})
+		cancelOnce   sync.Once
+		err          error
+	)
+
+	callFunc = client.callChain(interceptorArray, func(ctx context.Context, requestUnit *RequestUnit) (*ResponseUnit, error) {
+		if !callUnit.arrive() {
+			return nil, fmt.Errorf("call %s already executed", callUnit.requestUnit.Method)
+		}
+
+		callUnit.requestUnit = requestUnit
+		callUnit.submitted = true
+		callChan <- callUnit
+
+		<-callUnit.responseDone
+
+		return callUnit.responseUnit, callUnit.err
+	})
+
+	if executeUnit.context.Err() == nil {
+		if executeUnit.cancelFunc != nil {
+			go func() {
+				select {
+				case <-executeUnit.context.Done():
+					cancelOnce.Do(executeUnit.cancelFunc)
+				case <-watchMutex:
+				}
+			}()
+		}
+
+		responseUnit, err = callFunc(executeUnit.context, callUnit.requestUnit)
+		close(watchMutex)
+
+		if err != nil && executeUnit.context.Err() != nil && executeUnit.cancelFunc != nil {
+			go cancelOnce.Do(executeUnit.cancelFunc)
 		}
+	} else {
+		err = executeUnit.context.Err()
+	}
+
+	if callUnit.arrive() {
+		callChan <- callUnit
+	}
 
-		executeUnit.executeMutex <- true
+	switch {
+	case err != nil && executeUnit.context.Err() != nil:
+		executeUnit.error = NewErrorRequestCancelled(executeUnit.context.Err().Error())
+	case err != nil:
+		executeUnit.error = newErrorTransport(err)
+	case responseUnit != nil:
+		executeUnit.result = responseUnit.Result
+		executeUnit.error = responseUnit.Error
+	case executeUnit.id != nil:
+		executeUnit.error = NewErrorInternalError(nil)
 	}
+
+	executeUnit.executeMutex <- true
 }
 
 func (client *Client) Execute(withIndex bool, method string, option interface{}) (executeUnit *clientExecuteUnit) {
+	return client.ExecuteContext(context.Background(), withIndex, method, option)
+}
+
+func (client *Client) ExecuteContext(ctx context.Context, withIndex bool, method string, option interface{}) (executeUnit *clientExecuteUnit) {
+	return client.executeContext(ctx, withIndex, method, option, nil)
+}
+
+func (client *Client) executeContext(ctx context.Context, withIndex bool, method string, option interface{}, progressFunc ClientProgressFunc) (executeUnit *clientExecuteUnit) {
 	var err error
 
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	client.mutex <- true
 	client.executeIndex++
 
 	executeUnit = &clientExecuteUnit{
 		controlMutex: make(chan interface{}, 1),
+		context:      ctx,
 		method:       method,
 	}
 
+	executeUnit.index = client.executeIndex
+
 	if withIndex {
-		executeUnit.index = client.executeIndex
-	} else {
-		executeUnit.index = -client.executeIndex
+		if client.idGenerator != nil {
+			executeUnit.id = client.idGenerator.NewID()
+		} else {
+			executeUnit.id = executeUnit.index
+		}
+
+		executeUnit.cancelFunc = client.cancel(executeUnit.id)
 	}
 
 	if option != nil {
@@ -223,6 +515,14 @@ func (client *Client) Execute(withIndex bool, method string, option interface{})
 		}
 	}
 
+	if progressFunc != nil {
+		executeUnit.option, executeUnit.doneFunc, executeUnit.error = client.progress(executeUnit.id, executeUnit.option, progressFunc)
+		if executeUnit.error != nil {
+			<-client.mutex
+			return
+		}
+	}
+
 	executeUnit.executeMutex = make(chan interface{}, 1)
 
 	if client.executeArray == nil {
@@ -237,7 +537,11 @@ func (client *Client) Execute(withIndex bool, method string, option interface{})
 }
 
 func (client *Client) Request(method string, option interface{}) clientExecuteRequest {
-	executeUnit := client.Execute(true, method, option)
+	return client.RequestContext(context.Background(), method, option)
+}
+
+func (client *Client) RequestContext(ctx context.Context, method string, option interface{}) clientExecuteRequest {
+	executeUnit := client.ExecuteContext(ctx, true, method, option)
 
 	go client.execute()
 
@@ -245,12 +549,20 @@ func (client *Client) Request(method string, option interface{}) clientExecuteRe
 }
 
 func (client *Client) DeferRequest(method string, option interface{}) clientExecuteRequest {
-	executeUnit := client.Execute(true, method, option)
+	return client.DeferRequestContext(context.Background(), method, option)
+}
+
+func (client *Client) DeferRequestContext(ctx context.Context, method string, option interface{}) clientExecuteRequest {
+	executeUnit := client.ExecuteContext(ctx, true, method, option)
 	return executeUnit
 }
 
 func (client *Client) Notification(method string, option interface{}) clientExecuteNotification {
-	executeUnit := client.Execute(false, method, option)
+	return client.NotificationContext(context.Background(), method, option)
+}
+
+func (client *Client) NotificationContext(ctx context.Context, method string, option interface{}) clientExecuteNotification {
+	executeUnit := client.ExecuteContext(ctx, false, method, option)
 
 	go client.execute()
 
@@ -258,7 +570,11 @@ func (client *Client) Notification(method string, option interface{}) clientExec
 }
 
 func (client *Client) DeferNotification(method string, option interface{}) clientExecuteNotification {
-	executeUnit := client.Execute(false, method, option)
+	return client.DeferNotificationContext(context.Background(), method, option)
+}
+
+func (client *Client) DeferNotificationContext(ctx context.Context, method string, option interface{}) clientExecuteNotification {
+	executeUnit := client.ExecuteContext(ctx, false, method, option)
 	return executeUnit
 }
 
@@ -269,6 +585,11 @@ func NewClient(clientTransport ClientTransport) *Client {
 
 		executeIndex: 0,
 		executeArray: nil,
+
+		cancelMethod: "$/cancelRequest",
+
+		progressMethod:     "$/progress",
+		progressTokenField: "progressToken",
 	}
 }
 
@@ -0,0 +1,42 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+)
+
+//--------------------------------------------------------------------------------//
+// CLIENT CALL
+//--------------------------------------------------------------------------------//
+
+func newCallParams(argumentArray []interface{}) interface{} {
+	switch len(argumentArray) {
+	case 0:
+		return nil
+	case 1:
+		argumentJson, err := json.Marshal(argumentArray[0])
+		if err == nil && len(argumentJson) > 0 && argumentJson[0] == '{' {
+			return json.RawMessage(argumentJson)
+		}
+	}
+
+	return argumentArray
+}
+
+func (client *Client) Call(method string, result interface{}, argumentArray ...interface{}) *Error {
+	return client.CallContext(context.Background(), method, result, argumentArray...)
+}
+
+func (client *Client) CallContext(ctx context.Context, method string, result interface{}, argumentArray ...interface{}) *Error {
+	return client.RequestContext(ctx, method, newCallParams(argumentArray)).Response(result)
+}
+
+func (client *Client) DeferCall(method string, argumentArray ...interface{}) clientExecuteRequest {
+	return client.DeferCallContext(context.Background(), method, argumentArray...)
+}
+
+func (client *Client) DeferCallContext(ctx context.Context, method string, argumentArray ...interface{}) clientExecuteRequest {
+	return client.DeferRequestContext(ctx, method, newCallParams(argumentArray))
+}
+
+//--------------------------------------------------------------------------------//
@@ -0,0 +1,142 @@
+package jsonrpc2
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+//--------------------------------------------------------------------------------//
+// CLIENT ID
+//--------------------------------------------------------------------------------//
+
+type ClientIDGenerator interface {
+	NewID() interface{}
+}
+
+type ClientIDFunc func() interface{}
+
+func (idFunc ClientIDFunc) NewID() interface{} {
+	return idFunc()
+}
+
+func (client *Client) SetIDGenerator(idGenerator ClientIDGenerator) {
+	client.mutex <- true
+	client.idGenerator = idGenerator
+	<-client.mutex
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT ID SEQUENTIAL
+//--------------------------------------------------------------------------------//
+
+type clientIDSequential struct {
+	mutex chan interface{}
+	index int64
+}
+
+func (idGenerator *clientIDSequential) next() int64 {
+	idGenerator.mutex <- true
+	idGenerator.index++
+	index := idGenerator.index
+	<-idGenerator.mutex
+
+	return index
+}
+
+func (idGenerator *clientIDSequential) NewID() interface{} {
+	return idGenerator.next()
+}
+
+func NewClientIDSequential() ClientIDGenerator {
+	return &clientIDSequential{
+		mutex: make(chan interface{}, 1),
+	}
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT ID PREFIX
+//--------------------------------------------------------------------------------//
+
+type clientIDPrefix struct {
+	clientIDSequential
+
+	prefix string
+}
+
+func (idGenerator *clientIDPrefix) NewID() interface{} {
+	return idGenerator.prefix + strconv.FormatInt(idGenerator.next(), 10)
+}
+
+func NewClientIDPrefix(prefix string) ClientIDGenerator {
+	return &clientIDPrefix{
+		clientIDSequential: clientIDSequential{
+			mutex: make(chan interface{}, 1),
+		},
+
+		prefix: prefix,
+	}
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT ID UUID
+//--------------------------------------------------------------------------------//
+
+func NewClientIDUUID() ClientIDGenerator {
+	return ClientIDFunc(func() interface{} {
+		var uuid [16]byte
+
+		rand.Read(uuid[:])
+
+		uuid[6] = uuid[6]&0x0f | 0x40
+		uuid[8] = uuid[8]&0x3f | 0x80
+
+		return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+	})
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT ID ULID
+//--------------------------------------------------------------------------------//
+
+const clientIDULIDAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func NewClientIDULID() ClientIDGenerator {
+	return ClientIDFunc(func() interface{} {
+		var (
+			ulid       [16]byte
+			ulidText   [26]byte
+			ulidHigh   uint64
+			ulidLow    uint64
+			ulidBitPos uint
+		)
+
+		binary.BigEndian.PutUint64(ulid[0:8], uint64(time.Now().UnixNano()/int64(time.Millisecond))<<16)
+		rand.Read(ulid[6:16])
+
+		ulidHigh = binary.BigEndian.Uint64(ulid[0:8])
+		ulidLow = binary.BigEndian.Uint64(ulid[8:16])
+
+		for textIndex := len(ulidText) - 1; textIndex >= 0; textIndex-- {
+			ulidBitPos = uint(len(ulidText)-1-textIndex) * 5
+
+			var symbol uint64
+			switch {
+			case ulidBitPos+5 <= 64:
+				symbol = ulidLow >> ulidBitPos
+			case ulidBitPos < 64:
+				symbol = ulidLow>>ulidBitPos | ulidHigh<<(64-ulidBitPos)
+			default:
+				symbol = ulidHigh >> (ulidBitPos - 64)
+			}
+
+			ulidText[textIndex] = clientIDULIDAlphabet[symbol&0x1f]
+		}
+
+		return string(ulidText[:])
+	})
+}
+
+//--------------------------------------------------------------------------------//
@@ -0,0 +1,55 @@
+package jsonrpc2
+
+import "context"
+
+//--------------------------------------------------------------------------------//
+// CLIENT INTERCEPTOR
+//--------------------------------------------------------------------------------//
+
+type ClientTransportFunc func(context.Context, RequestSlice) (ResponseSlice, error)
+
+type ClientTransportInterceptor func(ClientTransportFunc) ClientTransportFunc
+
+type ClientCallFunc func(context.Context, *RequestUnit) (*ResponseUnit, error)
+
+type ClientCallInterceptor func(ClientCallFunc) ClientCallFunc
+
+func (client *Client) UseTransport(interceptorArray ...ClientTransportInterceptor) {
+	client.mutex <- true
+	client.transportInterceptorArray = append(client.transportInterceptorArray, interceptorArray...)
+	<-client.mutex
+}
+
+func (client *Client) UseCall(interceptorArray ...ClientCallInterceptor) {
+	client.mutex <- true
+	client.callInterceptorArray = append(client.callInterceptorArray, interceptorArray...)
+	<-client.mutex
+}
+
+func (client *Client) transportExecute(ctx context.Context, requestSlice RequestSlice) (ResponseSlice, error) {
+	if transportContext, ok := client.transport.(ClientTransportContext); ok {
+		return transportContext.ExecuteContext(ctx, requestSlice)
+	}
+
+	return client.transport.Execute(requestSlice)
+}
+
+func (client *Client) transportChain(interceptorArray []ClientTransportInterceptor) (transportFunc ClientTransportFunc) {
+	transportFunc = client.transportExecute
+
+	for interceptorIndex := len(interceptorArray) - 1; interceptorIndex >= 0; interceptorIndex-- {
+		transportFunc = interceptorArray[interceptorIndex](transportFunc)
+	}
+
+	return
+}
+
+func (client *Client) callChain(interceptorArray []ClientCallInterceptor, callFunc ClientCallFunc) ClientCallFunc {
+	for interceptorIndex := len(interceptorArray) - 1; interceptorIndex >= 0; interceptorIndex-- {
+		callFunc = interceptorArray[interceptorIndex](callFunc)
+	}
+
+	return callFunc
+}
+
+//--------------------------------------------------------------------------------//
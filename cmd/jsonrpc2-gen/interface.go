@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//--------------------------------------------------------------------------------//
+// GENERATE INTERFACE
+//--------------------------------------------------------------------------------//
+
+type interfaceSource struct {
+	fileSet    *token.FileSet
+	file       *ast.File
+	importMap  map[string]string
+	importUsed map[string]string
+}
+
+func newInterfaceSource(sourcePath string) (source *interfaceSource, err error) {
+	var importName string
+
+	source = &interfaceSource{
+		fileSet:    token.NewFileSet(),
+		importMap:  map[string]string{},
+		importUsed: map[string]string{},
+	}
+
+	source.file, err = parser.ParseFile(source.fileSet, sourcePath, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, importSpec := range source.file.Imports {
+		importPath, _ := strconv.Unquote(importSpec.Path.Value)
+
+		importName = path.Base(importPath)
+		if importSpec.Name != nil {
+			importName = importSpec.Name.Name
+		}
+
+		source.importMap[importName] = importPath
+	}
+
+	return
+}
+
+func (source *interfaceSource) expr(node ast.Expr) string {
+	var exprBuffer bytes.Buffer
+
+	ast.Inspect(node, func(child ast.Node) bool {
+		if selectorExpr, ok := child.(*ast.SelectorExpr); ok {
+			if packageIdent, ok := selectorExpr.X.(*ast.Ident); ok && source.importMap[packageIdent.Name] != "" {
+				source.importUsed[source.importMap[packageIdent.Name]] = packageIdent.Name
+			}
+		}
+
+		return true
+	})
+
+	printer.Fprint(&exprBuffer, source.fileSet, node)
+
+	return exprBuffer.String()
+}
+
+func (source *interfaceSource) lookup(interfaceName string) (interfaceType *ast.InterfaceType, err error) {
+	for _, decl := range source.file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if typeSpec.Name.Name != interfaceName {
+				continue
+			}
+
+			interfaceType, ok = typeSpec.Type.(*ast.InterfaceType)
+			if !ok {
+				return nil, fmt.Errorf("type %s is not an interface", interfaceName)
+			}
+
+			return
+		}
+	}
+
+	return nil, fmt.Errorf("interface %s not found", interfaceName)
+}
+
+func generateInterface(sourcePath string, interfaceName string, packageName string, typeName string, namespace string, separator string, lowercase bool) (gen *generator, err error) {
+	var (
+		source        *interfaceSource
+		interfaceType *ast.InterfaceType
+	)
+
+	if sourcePath == "" {
+		return nil, fmt.Errorf("-source is required with -interface")
+	}
+
+	source, err = newInterfaceSource(sourcePath)
+	if err != nil {
+		return
+	}
+
+	interfaceType, err = source.lookup(interfaceName)
+	if err != nil {
+		return
+	}
+
+	if typeName == "" {
+		typeName = interfaceName + "Client"
+	}
+
+	gen = newGenerator(packageName, typeName)
+
+	for _, field := range interfaceType.Methods.List {
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("interface %s: embedded interface %s is not supported", interfaceName, source.expr(field.Type))
+		}
+
+		err = source.generateMethod(gen, field.Names[0].Name, field.Type.(*ast.FuncType), namespace, separator, lowercase)
+		if err != nil {
+			return nil, fmt.Errorf("interface %s: %s", interfaceName, err.Error())
+		}
+	}
+
+	for importPath, importName := range source.importUsed {
+		if path.Base(importPath) == importName {
+			importName = ""
+		}
+
+		gen.addImport(importPath, importName)
+	}
+
+	return
+}
+
+func (source *interfaceSource) generateMethod(gen *generator, goName string, funcType *ast.FuncType, namespace string, separator string, lowercase bool) (err error) {
+	var (
+		method        = goName
+		withContext   bool
+		paramArray    []string
+		argumentArray []string
+		resultType    string
+		fieldArray    []*ast.Field
+	)
+
+	if lowercase {
+		methodRune, methodRuneSize := utf8.DecodeRuneInString(method)
+		method = string(unicode.ToLower(methodRune)) + method[methodRuneSize:]
+	}
+
+	method = namespace + separator + method
+
+	fieldArray = funcType.Params.List
+	if len(fieldArray) > 0 && source.expr(fieldArray[0].Type) == "context.Context" {
+		withContext = true
+
+		if len(fieldArray[0].Names) > 1 {
+			return fmt.Errorf("method %s must accept context.Context only as the first argument", goName)
+		}
+
+		fieldArray = fieldArray[1:]
+	}
+
+	for _, field := range fieldArray {
+		fieldType := source.expr(field.Type)
+
+		if fieldType == "context.Context" {
+			return fmt.Errorf("method %s must accept context.Context only as the first argument", goName)
+		}
+
+		if strings.HasPrefix(fieldType, "...") {
+			return fmt.Errorf("method %s must not be variadic", goName)
+		}
+
+		if len(field.Names) == 0 {
+			argumentName := fmt.Sprintf("arg%d", len(argumentArray))
+			paramArray = append(paramArray, argumentName+" "+fieldType)
+			argumentArray = append(argumentArray, argumentName)
+			continue
+		}
+
+		for _, name := range field.Names {
+			paramArray = append(paramArray, name.Name+" "+fieldType)
+			argumentArray = append(argumentArray, name.Name)
+		}
+	}
+
+	switch {
+	case funcType.Results == nil:
+		return fmt.Errorf("method %s must return (result, error) or error", goName)
+	case funcType.Results.NumFields() == 1 && source.expr(funcType.Results.List[0].Type) == "error":
+
+	case funcType.Results.NumFields() == 2 && source.expr(funcType.Results.List[len(funcType.Results.List)-1].Type) == "error":
+		resultType = source.expr(funcType.Results.List[0].Type)
+	default:
+		return fmt.Errorf("method %s must return (result, error) or error", goName)
+	}
+
+	gen.writeStub(goName, paramArray, func(resultExpr string) string {
+		return fmt.Sprintf("stub.client.CallContext(%s)", strings.Join(append([]string{"ctx", strconv.Quote(method), resultExpr}, argumentArray...), ", "))
+	}, resultType, withContext, nil)
+
+	return
+}
+
+//--------------------------------------------------------------------------------//
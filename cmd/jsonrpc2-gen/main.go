@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//--------------------------------------------------------------------------------//
+// GENERATOR
+//--------------------------------------------------------------------------------//
+
+const importJsonRPC = "github.com/GlshchnkLx/go-jsonrpc2"
+
+type generator struct {
+	packageName string
+	typeName    string
+
+	importMap   map[string]string
+	declBuffer  bytes.Buffer
+	declMap     map[string]bool
+	errorBuffer bytes.Buffer
+	stubBuffer  bytes.Buffer
+}
+
+type generatorError struct {
+	code     int32
+	typeName string
+}
+
+func newGenerator(packageName string, typeName string) *generator {
+	return &generator{
+		packageName: packageName,
+		typeName:    typeName,
+		importMap: map[string]string{
+			importJsonRPC: "",
+		},
+		declMap: map[string]bool{},
+	}
+}
+
+func (gen *generator) addImport(path string, name string) {
+	gen.importMap[path] = name
+}
+
+func (gen *generator) addError(name string, code int32, message string) string {
+	for errorIndex, errorName := 2, name; gen.declMap[name]; errorIndex++ {
+		name = errorName + strconv.Itoa(errorIndex)
+	}
+
+	gen.declMap[name] = true
+
+	fmt.Fprintf(&gen.errorBuffer, "// %s is returned for error code %d", name, code)
+	if message != "" {
+		fmt.Fprintf(&gen.errorBuffer, " (%s)", message)
+	}
+
+	fmt.Fprintf(&gen.errorBuffer, ".\ntype %s struct {\n\tRPCError *%s.Error\n}\n\n", name, gen.jsonrpcName())
+	fmt.Fprintf(&gen.errorBuffer, "func (err *%s) Error() string {\n\treturn err.RPCError.Error()\n}\n\n", name)
+	fmt.Fprintf(&gen.errorBuffer, "func (err *%s) ErrorRPC() *%s.Error {\n\treturn err.RPCError\n}\n\n", name, gen.jsonrpcName())
+
+	return name
+}
+
+func (gen *generator) jsonrpcName() string {
+	if gen.importMap[importJsonRPC] != "" {
+		return gen.importMap[importJsonRPC]
+	}
+
+	return "jsonrpc2"
+}
+
+func (gen *generator) source() ([]byte, error) {
+	var (
+		output    bytes.Buffer
+		pathArray []string
+	)
+
+	fmt.Fprintf(&output, "// Code generated by jsonrpc2-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&output, "package %s\n\n", gen.packageName)
+
+	for path := range gen.importMap {
+		pathArray = append(pathArray, path)
+	}
+
+	sort.Strings(pathArray)
+
+	fmt.Fprintf(&output, "import (\n")
+	for _, standard := range []bool{true, false} {
+		for _, path := range pathArray {
+			if strings.Contains(strings.Split(path, "/")[0], ".") != standard {
+				fmt.Fprintf(&output, "\t%s %q\n", gen.importMap[path], path)
+			}
+		}
+
+		fmt.Fprintf(&output, "\n")
+	}
+	fmt.Fprintf(&output, ")\n\n")
+
+	jsonrpcName := gen.jsonrpcName()
+
+	output.Write(gen.errorBuffer.Bytes())
+	output.Write(gen.declBuffer.Bytes())
+
+	fmt.Fprintf(&output, "type %s struct {\n\tclient *%s.Client\n}\n\n", gen.typeName, jsonrpcName)
+	fmt.Fprintf(&output, "func New%s(client *%s.Client) *%s {\n\treturn &%s{client: client}\n}\n\n", gen.typeName, jsonrpcName, gen.typeName, gen.typeName)
+	fmt.Fprintf(&output, "func (stub *%s) Client() *%s.Client {\n\treturn stub.client\n}\n\n", gen.typeName, jsonrpcName)
+
+	output.Write(gen.stubBuffer.Bytes())
+
+	return format.Source(output.Bytes())
+}
+
+func (gen *generator) writeStub(goName string, paramArray []string, callFunc func(resultExpr string) string, resultType string, withContext bool, errorArray []generatorError, headerArray ...string) {
+	var resultReturn string
+
+	gen.addImport("context", "")
+
+	if withContext {
+		paramArray = append([]string{"ctx context.Context"}, paramArray...)
+	}
+
+	if resultType == "" {
+		fmt.Fprintf(&gen.stubBuffer, "func (stub *%s) %s(%s) error {\n", gen.typeName, goName, strings.Join(paramArray, ", "))
+	} else {
+		fmt.Fprintf(&gen.stubBuffer, "func (stub *%s) %s(%s) (%s, error) {\n", gen.typeName, goName, strings.Join(paramArray, ", "), resultType)
+	}
+
+	if !withContext {
+		fmt.Fprintf(&gen.stubBuffer, "\tctx := context.Background()\n\n")
+	}
+
+	for _, header := range headerArray {
+		fmt.Fprintf(&gen.stubBuffer, "%s\n", header)
+	}
+
+	if resultType == "" {
+		fmt.Fprintf(&gen.stubBuffer, "\tif rpcError := %s; rpcError != nil {\n", callFunc("nil"))
+	} else {
+		resultReturn = "rpcResult, "
+
+		fmt.Fprintf(&gen.stubBuffer, "\tvar rpcResult %s\n\n", resultType)
+		fmt.Fprintf(&gen.stubBuffer, "\tif rpcError := %s; rpcError != nil {\n", callFunc("&rpcResult"))
+	}
+
+	if len(errorArray) > 0 {
+		fmt.Fprintf(&gen.stubBuffer, "\t\tswitch rpcError.Code {\n")
+		for _, errorUnit := range errorArray {
+			fmt.Fprintf(&gen.stubBuffer, "\t\tcase %d:\n\t\t\treturn %s&%s{RPCError: rpcError}\n", errorUnit.code, resultReturn, errorUnit.typeName)
+		}
+		fmt.Fprintf(&gen.stubBuffer, "\t\t}\n\n")
+	}
+
+	fmt.Fprintf(&gen.stubBuffer, "\t\treturn %srpcError\n\t}\n\n\treturn %snil\n}\n\n", resultReturn, resultReturn)
+}
+
+func goIdentifier(name string) string {
+	var (
+		output    strings.Builder
+		wordStart = true
+	)
+
+	for _, nameRune := range name {
+		if !unicode.IsLetter(nameRune) && !unicode.IsDigit(nameRune) {
+			wordStart = true
+			continue
+		}
+
+		if output.Len() == 0 && unicode.IsDigit(nameRune) {
+			output.WriteRune('X')
+		}
+
+		if wordStart {
+			nameRune = unicode.ToUpper(nameRune)
+			wordStart = false
+		}
+
+		output.WriteRune(nameRune)
+	}
+
+	if output.Len() == 0 {
+		return "X"
+	}
+
+	return output.String()
+}
+
+//--------------------------------------------------------------------------------//
+// MAIN
+//--------------------------------------------------------------------------------//
+
+func main() {
+	var (
+		flagOpenRPC   = flag.String("openrpc", "", "OpenRPC document to generate the client from")
+		flagInterface = flag.String("interface", "", "Go interface in -source to generate the client from")
+		flagSource    = flag.String("source", os.Getenv("GOFILE"), "Go source file declaring -interface")
+		flagPackage   = flag.String("package", os.Getenv("GOPACKAGE"), "package name of the generated file")
+		flagType      = flag.String("type", "", "name of the generated client type")
+		flagNamespace = flag.String("namespace", "", "service name the methods are registered under (default: interface name)")
+		flagSeparator = flag.String("separator", ".", "separator between namespace and method name")
+		flagLowercase = flag.Bool("lowercase", false, "lowercase the first letter of method names")
+		flagTrim      = flag.String("trim", "", "prefix trimmed from OpenRPC method names before naming Go methods")
+		flagOutput    = flag.String("output", "", "output file (default: stdout)")
+
+		gen    *generator
+		output []byte
+		err    error
+	)
+
+	flag.Parse()
+
+	if *flagPackage == "" {
+		*flagPackage = "main"
+	}
+
+	switch {
+	case *flagOpenRPC != "" && *flagInterface == "":
+		gen, err = generateOpenRPC(*flagOpenRPC, *flagPackage, *flagType, *flagTrim)
+	case *flagInterface != "" && *flagOpenRPC == "":
+		if *flagNamespace == "" {
+			*flagNamespace = *flagInterface
+		}
+
+		gen, err = generateInterface(*flagSource, *flagInterface, *flagPackage, *flagType, *flagNamespace, *flagSeparator, *flagLowercase)
+	default:
+		err = fmt.Errorf("exactly one of -openrpc or -interface is required")
+	}
+
+	if err == nil {
+		output, err = gen.source()
+	}
+
+	if err == nil {
+		if *flagOutput == "" {
+			_, err = os.Stdout.Write(output)
+		} else {
+			err = ioutil.WriteFile(*flagOutput, output, 0644)
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jsonrpc2-gen: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+//--------------------------------------------------------------------------------//
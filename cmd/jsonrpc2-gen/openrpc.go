@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsonrpc2 "github.com/GlshchnkLx/go-jsonrpc2"
+)
+
+//--------------------------------------------------------------------------------//
+// GENERATE OPENRPC
+//--------------------------------------------------------------------------------//
+
+func generateOpenRPC(documentPath string, packageName string, typeName string, trim string) (gen *generator, err error) {
+	var (
+		documentJson []byte
+		document     jsonrpc2.OpenRPC
+		nameArray    []string
+	)
+
+	documentJson, err = ioutil.ReadFile(documentPath)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(documentJson, &document)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", documentPath, err.Error())
+	}
+
+	if typeName == "" {
+		typeName = "Client"
+		if document.Info.Title != "" {
+			typeName = goIdentifier(document.Info.Title) + "Client"
+		}
+	}
+
+	gen = newGenerator(packageName, typeName)
+
+	if document.Components != nil {
+		for name := range document.Components.Schemas {
+			nameArray = append(nameArray, name)
+		}
+
+		sort.Strings(nameArray)
+
+		for _, name := range nameArray {
+			gen.declareSchema(goIdentifier(name), document.Components.Schemas[name])
+		}
+	}
+
+	for _, method := range document.Methods {
+		err = gen.generateMethod(method, trim)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %s", method.Name, err.Error())
+		}
+	}
+
+	return
+}
+
+func (gen *generator) declareSchema(typeName string, schema *jsonrpc2.Schema) {
+	if schema != nil && schema.Type == "object" && len(schema.Properties) > 0 {
+		gen.declareStruct(typeName, schema)
+		return
+	}
+
+	if gen.declMap[typeName] {
+		return
+	}
+
+	gen.declMap[typeName] = true
+
+	fmt.Fprintf(&gen.declBuffer, "type %s = %s\n\n", typeName, gen.schemaType(schema, typeName))
+}
+
+func (gen *generator) declareStruct(typeName string, schema *jsonrpc2.Schema) {
+	var (
+		nameArray   []string
+		requiredMap = map[string]bool{}
+		fieldArray  []string
+		fieldType   string
+	)
+
+	if gen.declMap[typeName] {
+		return
+	}
+
+	gen.declMap[typeName] = true
+
+	for _, name := range schema.Required {
+		requiredMap[name] = true
+	}
+
+	for name := range schema.Properties {
+		nameArray = append(nameArray, name)
+	}
+
+	sort.Strings(nameArray)
+
+	for _, name := range nameArray {
+		fieldTag := name
+		if !requiredMap[name] {
+			fieldTag += ",omitempty"
+		}
+
+		fieldName := goIdentifier(name)
+		fieldType = gen.schemaType(schema.Properties[name], typeName+fieldName)
+
+		if propertySchema := schema.Properties[name]; !requiredMap[name] && propertySchema != nil && (propertySchema.Ref != "" || (propertySchema.Type == "object" && len(propertySchema.Properties) > 0)) {
+			fieldType = "*" + fieldType
+		}
+
+		fieldArray = append(fieldArray, fmt.Sprintf("\t%s %s `json:%q`\n", fieldName, fieldType, fieldTag))
+	}
+
+	if schema.Description != "" {
+		fmt.Fprintf(&gen.declBuffer, "// %s %s\n", typeName, schema.Description)
+	}
+
+	fmt.Fprintf(&gen.declBuffer, "type %s struct {\n%s}\n\n", typeName, strings.Join(fieldArray, ""))
+}
+
+func (gen *generator) schemaType(schema *jsonrpc2.Schema, typeHint string) string {
+	if schema == nil {
+		gen.addImport("encoding/json", "")
+		return "json.RawMessage"
+	}
+
+	if schema.Ref != "" {
+		return goIdentifier(schema.Ref[strings.LastIndex(schema.Ref, "/")+1:])
+	}
+
+	switch schema.Type {
+	case "boolean":
+		return "bool"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			gen.addImport("time", "")
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+
+		return "string"
+	case "array":
+		return "[]" + gen.schemaType(schema.Items, typeHint+"Item")
+	case "object":
+		if len(schema.Properties) > 0 {
+			gen.declareStruct(typeHint, schema)
+			return typeHint
+		}
+
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + gen.schemaType(schema.AdditionalProperties, typeHint+"Value")
+		}
+
+		return "map[string]interface{}"
+	}
+
+	gen.addImport("encoding/json", "")
+
+	return "json.RawMessage"
+}
+
+func (gen *generator) generateMethod(method *jsonrpc2.OpenRPCMethod, trim string) (err error) {
+	var (
+		goName        = goIdentifier(strings.TrimPrefix(method.Name, trim))
+		paramArray    []string
+		argumentArray []string
+		optionalArray []string
+		resultType    string
+		paramsExpr    = "nil"
+		errorArray    []generatorError
+		errorCodeMap  = map[int32]bool{}
+	)
+
+	for _, errorUnit := range method.Errors {
+		if errorUnit == nil || errorCodeMap[errorUnit.Code] {
+			continue
+		}
+
+		errorCodeMap[errorUnit.Code] = true
+		errorArray = append(errorArray, generatorError{
+			code:     errorUnit.Code,
+			typeName: gen.addError(goName+"Error"+goIdentifier(errorUnit.Message), errorUnit.Code, errorUnit.Message),
+		})
+	}
+
+	if method.Result != nil && (method.Result.Schema == nil || method.Result.Schema.Type != "null") {
+		resultType = gen.schemaType(method.Result.Schema, goName+"Result")
+	}
+
+	switch {
+	case method.ParamStructure == "by-name" && len(method.Params) > 0:
+		paramsSchema := &jsonrpc2.Schema{Type: "object", Properties: map[string]*jsonrpc2.Schema{}}
+
+		for _, param := range method.Params {
+			paramsSchema.Properties[param.Name] = param.Schema
+			if param.Required {
+				paramsSchema.Required = append(paramsSchema.Required, param.Name)
+			}
+		}
+
+		gen.declareStruct(goName+"Params", paramsSchema)
+
+		paramArray = []string{"params " + goName + "Params"}
+		paramsExpr = "params"
+	case len(method.Params) > 0:
+		for paramIndex, param := range method.Params {
+			argumentName := safeIdentifier(param.Name, paramIndex)
+			argumentType := gen.schemaType(param.Schema, goName+goIdentifier(param.Name))
+
+			if !param.Required {
+				argumentType = "*" + argumentType
+				optionalArray = append(optionalArray, argumentName)
+			} else if len(optionalArray) > 0 {
+				return fmt.Errorf("required param %s follows an optional one", param.Name)
+			}
+
+			paramArray = append(paramArray, argumentName+" "+argumentType)
+			argumentArray = append(argumentArray, argumentName)
+		}
+
+		paramsExpr = "rpcParams"
+	}
+
+	if len(argumentArray) > 0 {
+		gen.writeStubPositional(goName, method.Name, paramArray, argumentArray, optionalArray, resultType, errorArray)
+		return
+	}
+
+	gen.writeStub(goName, paramArray, func(resultExpr string) string {
+		return fmt.Sprintf("stub.client.RequestContext(ctx, %s, %s).Response(%s)", strconv.Quote(method.Name), paramsExpr, resultExpr)
+	}, resultType, true, errorArray)
+
+	return
+}
+
+func (gen *generator) writeStubPositional(goName string, method string, paramArray []string, argumentArray []string, optionalArray []string, resultType string, errorArray []generatorError) {
+	var stubHeader strings.Builder
+
+	fmt.Fprintf(&stubHeader, "rpcParams := []interface{}{%s}\n", strings.Join(argumentArray, ", "))
+
+	for optionalIndex := len(optionalArray) - 1; optionalIndex >= 0; optionalIndex-- {
+		argumentIndex := len(argumentArray) - len(optionalArray) + optionalIndex
+		fmt.Fprintf(&stubHeader, "if len(rpcParams) == %d && %s == nil {\nrpcParams = rpcParams[:%d]\n}\n", argumentIndex+1, argumentArray[argumentIndex], argumentIndex)
+	}
+
+	gen.writeStub(goName, paramArray, func(resultExpr string) string {
+		return fmt.Sprintf("stub.client.RequestContext(ctx, %s, rpcParams).Response(%s)", strconv.Quote(method), resultExpr)
+	}, resultType, true, errorArray, stubHeader.String())
+}
+
+func safeIdentifier(name string, index int) string {
+	identifier := goIdentifier(name)
+	identifier = strings.ToLower(identifier[:1]) + identifier[1:]
+
+	switch identifier {
+	case "ctx", "stub", "rpcParams", "rpcResult", "rpcError",
+		"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for",
+		"func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return",
+		"select", "struct", "switch", "type", "var":
+		return fmt.Sprintf("%s%d", identifier, index)
+	}
+
+	return identifier
+}
+
+//--------------------------------------------------------------------------------//
@@ -0,0 +1,319 @@
+package jsonrpc2
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+//--------------------------------------------------------------------------------//
+// CODEC
+//--------------------------------------------------------------------------------//
+
+type Codec interface {
+	ReadMessage() (json.RawMessage, error)
+	WriteMessage(json.RawMessage) error
+	Close() error
+}
+
+type CodecFunc func(io.ReadWriteCloser) Codec
+
+func NewCodecFuncStream() CodecFunc {
+	return func(readWriteCloser io.ReadWriteCloser) Codec {
+		return NewCodecStream(readWriteCloser)
+	}
+}
+
+func NewCodecFuncHeader(contentType string) CodecFunc {
+	return func(readWriteCloser io.ReadWriteCloser) Codec {
+		codec := NewCodecHeader(readWriteCloser)
+		codec.SetContentType(contentType)
+		return codec
+	}
+}
+
+//--------------------------------------------------------------------------------//
+// CODEC STREAM
+//--------------------------------------------------------------------------------//
+
+type CodecStream struct {
+	Codec
+
+	stream      io.ReadWriteCloser
+	readDecoder *json.Decoder
+	readError   error
+
+	writeMutex chan interface{}
+	writer     *bufio.Writer
+}
+
+func (codec *CodecStream) ReadMessage() (message json.RawMessage, err error) {
+	if codec.readError != nil {
+		return nil, codec.readError
+	}
+
+	err = codec.readDecoder.Decode(&message)
+	if err != nil {
+		message = nil
+
+		switch err.(type) {
+		case *json.SyntaxError, *json.UnmarshalTypeError:
+			codec.readError = io.EOF
+			return nil, NewErrorParseError(err.Error())
+		}
+
+		if err == io.ErrUnexpectedEOF {
+			codec.readError = io.EOF
+			return nil, NewErrorParseError(err.Error())
+		}
+	}
+
+	return
+}
+
+func (codec *CodecStream) WriteMessage(message json.RawMessage) (err error) {
+	codec.writeMutex <- true
+	defer func() {
+		<-codec.writeMutex
+	}()
+
+	_, err = codec.writer.Write(message)
+	if err != nil {
+		return
+	}
+
+	err = codec.writer.WriteByte('\n')
+	if err != nil {
+		return
+	}
+
+	return codec.writer.Flush()
+}
+
+func (codec *CodecStream) Close() error {
+	return codec.stream.Close()
+}
+
+func NewCodecStream(readWriteCloser io.ReadWriteCloser) *CodecStream {
+	return &CodecStream{
+		stream:      readWriteCloser,
+		readDecoder: json.NewDecoder(readWriteCloser),
+
+		writeMutex: make(chan interface{}, 1),
+		writer:     bufio.NewWriter(readWriteCloser),
+	}
+}
+
+//--------------------------------------------------------------------------------//
+// CODEC HEADER
+//--------------------------------------------------------------------------------//
+
+const codecHeaderMaxMessageSize = 64 << 20
+
+type CodecHeader struct {
+	Codec
+
+	stream         io.ReadWriteCloser
+	reader         *bufio.Reader
+	readError      error
+	contentType    string
+	maxMessageSize int64
+
+	writeMutex chan interface{}
+	writer     *bufio.Writer
+}
+
+func (codec *CodecHeader) readHeader() (contentLength int64, headerError *Error, err error) {
+	var (
+		headerLine  string
+		headerName  string
+		headerValue string
+		headerIndex int
+	)
+
+	contentLength = -1
+
+	for lineIndex := 0; ; lineIndex++ {
+		headerLine, err = codec.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && (lineIndex > 0 || headerLine != "") {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return
+		}
+
+		headerLine = strings.TrimRight(headerLine, "\r\n")
+		if headerLine == "" {
+			break
+		}
+
+		headerIndex = strings.IndexByte(headerLine, ':')
+		if headerIndex <= 0 {
+			if headerError == nil {
+				headerError = NewErrorParseError(fmt.Sprintf("malformed header %q", headerLine))
+			}
+
+			continue
+		}
+
+		headerName = strings.TrimSpace(headerLine[:headerIndex])
+		headerValue = strings.TrimSpace(headerLine[headerIndex+1:])
+
+		switch strings.ToLower(headerName) {
+		case "content-length":
+			contentLength, err = strconv.ParseInt(headerValue, 10, 64)
+			if err != nil || contentLength < 0 {
+				contentLength, err = -1, nil
+				headerError = NewErrorParseError(fmt.Sprintf("invalid Content-Length %q", headerValue))
+			}
+		case "content-type":
+			if headerError == nil {
+				headerError = codecCheckContentType(headerValue)
+			}
+		}
+	}
+
+	if headerError == nil && contentLength < 0 {
+		headerError = NewErrorParseError("missing Content-Length header")
+	}
+
+	return
+}
+
+func codecCheckContentType(contentType string) *Error {
+	mediaType, mediaParam, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return NewErrorParseError(fmt.Sprintf("invalid Content-Type %q", contentType))
+	}
+
+	switch mediaType {
+	case "application/json", "application/vscode-jsonrpc", "application/json-rpc":
+	default:
+		return NewErrorParseError(fmt.Sprintf("unsupported Content-Type %q", mediaType))
+	}
+
+	switch strings.ToLower(mediaParam["charset"]) {
+	case "", "utf-8", "utf8":
+	default:
+		return NewErrorParseError(fmt.Sprintf("unsupported charset %q", mediaParam["charset"]))
+	}
+
+	return nil
+}
+
+func (codec *CodecHeader) ReadMessage() (message json.RawMessage, err error) {
+	var (
+		contentLength int64
+		headerError   *Error
+	)
+
+	if codec.readError != nil {
+		return nil, codec.readError
+	}
+
+	contentLength, headerError, err = codec.readHeader()
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			codec.readError = io.EOF
+			return nil, NewErrorParseError(err.Error())
+		}
+
+		return nil, err
+	}
+
+	if contentLength < 0 {
+		codec.readError = io.EOF
+		return nil, headerError
+	}
+
+	if codec.maxMessageSize > 0 && contentLength > codec.maxMessageSize {
+		codec.readError = io.EOF
+		return nil, NewErrorParseError(fmt.Sprintf("Content-Length %d exceeds the maximum message size %d", contentLength, codec.maxMessageSize))
+	}
+
+	if contentLength > int64(^uint(0)>>1) {
+		codec.readError = io.EOF
+		return nil, NewErrorParseError(fmt.Sprintf("Content-Length %d is too large", contentLength))
+	}
+
+	message = make(json.RawMessage, contentLength)
+
+	_, err = io.ReadFull(codec.reader, message)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			codec.readError = io.EOF
+			return nil, NewErrorParseError(io.ErrUnexpectedEOF.Error())
+		}
+
+		return nil, err
+	}
+
+	if headerError != nil {
+		return nil, headerError
+	}
+
+	return
+}
+
+func (codec *CodecHeader) WriteMessage(message json.RawMessage) (err error) {
+	codec.writeMutex <- true
+	defer func() {
+		<-codec.writeMutex
+	}()
+
+	_, err = fmt.Fprintf(codec.writer, "Content-Length: %d\r\n", len(message))
+	if err != nil {
+		return
+	}
+
+	if codec.contentType != "" {
+		_, err = fmt.Fprintf(codec.writer, "Content-Type: %s\r\n", codec.contentType)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = codec.writer.WriteString("\r\n")
+	if err != nil {
+		return
+	}
+
+	_, err = codec.writer.Write(message)
+	if err != nil {
+		return
+	}
+
+	return codec.writer.Flush()
+}
+
+func (codec *CodecHeader) SetContentType(contentType string) {
+	codec.writeMutex <- true
+	codec.contentType = contentType
+	<-codec.writeMutex
+}
+
+func (codec *CodecHeader) SetMaxMessageSize(maxMessageSize int64) {
+	codec.maxMessageSize = maxMessageSize
+}
+
+func (codec *CodecHeader) Close() error {
+	return codec.stream.Close()
+}
+
+func NewCodecHeader(readWriteCloser io.ReadWriteCloser) *CodecHeader {
+	return &CodecHeader{
+		stream:         readWriteCloser,
+		reader:         bufio.NewReader(readWriteCloser),
+		maxMessageSize: codecHeaderMaxMessageSize,
+
+		writeMutex: make(chan interface{}, 1),
+		writer:     bufio.NewWriter(readWriteCloser),
+	}
+}
+
+//--------------------------------------------------------------------------------//
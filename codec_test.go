@@ -0,0 +1,66 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testReadWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (testReadWriteCloser) Close() error {
+	return nil
+}
+
+func TestCodecHeaderInvalidHeader(t *testing.T) {
+	const message = `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+
+	testArray := []struct {
+		name   string
+		header string
+	}{
+		{"content type first", "Content-Type: text/plain\r\nContent-Length: 40\r\n"},
+		{"content type last", "Content-Length: 40\r\nContent-Type: text/plain\r\n"},
+		{"malformed first", "malformed\r\nContent-Length: 40\r\n"},
+		{"malformed last", "Content-Length: 40\r\nmalformed\r\n"},
+	}
+
+	for _, test := range testArray {
+		input := test.header + "\r\n" + message + "Content-Length: 40\r\n\r\n" + message
+		codec := NewCodecHeader(testReadWriteCloser{bytes.NewBufferString(input)})
+
+		_, err := codec.ReadMessage()
+		if errorUnit, ok := err.(*Error); !ok || errorUnit.Code != -32700 {
+			t.Errorf("%s: expected a Parse error, got %v", test.name, err)
+			continue
+		}
+
+		nextMessage, err := codec.ReadMessage()
+		if err != nil || string(nextMessage) != message {
+			t.Errorf("%s: expected the next message to be read, got %q, %v", test.name, nextMessage, err)
+		}
+	}
+}
+
+func TestCodecHeaderMaxMessageSize(t *testing.T) {
+	testArray := []string{
+		"Content-Length: 9000000000000000000\r\n\r\n",
+		"Content-Length: 65\r\n\r\n" + strings.Repeat(" ", 65),
+	}
+
+	for _, input := range testArray {
+		codec := NewCodecHeader(testReadWriteCloser{bytes.NewBufferString(input + "Content-Length: 2\r\n\r\n{}")})
+		codec.SetMaxMessageSize(64)
+
+		_, err := codec.ReadMessage()
+		if errorUnit, ok := err.(*Error); !ok || errorUnit.Code != -32700 {
+			t.Errorf("%q: expected a Parse error, got %v", input, err)
+		}
+
+		if _, err = codec.ReadMessage(); err == nil {
+			t.Errorf("%q: expected the codec to stop reading", input)
+		}
+	}
+}
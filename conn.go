@@ -0,0 +1,398 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+//--------------------------------------------------------------------------------//
+// CONN
+//--------------------------------------------------------------------------------//
+
+type connContextKey struct{}
+
+func ConnFromContext(ctx context.Context) *Conn {
+	conn, _ := ctx.Value(connContextKey{}).(*Conn)
+	return conn
+}
+
+type Conn struct {
+	ClientTransport
+
+	codec  Codec
+	server *Server
+	client *Client
+
+	context   context.Context
+	cancel    context.CancelFunc
+	waitGroup sync.WaitGroup
+
+	mutex      chan interface{}
+	pendingMap map[string]chan *ResponseUnit
+
+	inflightMap map[string]*serverInflight
+	progressMap map[string]ClientProgressFunc
+
+	subscriptionMap       map[string]*Subscription
+	clientSubscriptionMap map[string]*ClientSubscription
+	subscribeWaitMap      map[string]int
+	subscribeOrphanMap    map[string][]json.RawMessage
+
+	closeMutex chan interface{}
+	closeError error
+
+	doneMutex chan interface{}
+	doneError error
+}
+
+func (conn *Conn) Server() *Server {
+	return conn.server
+}
+
+// Client returns the client bound to the connection and is the supported way to
+// call the peer. Its request IDs carry the "conn-" prefix, so a single client
+// created with NewClient over the same Conn does not collide with it.
+func (conn *Conn) Client() *Client {
+	return conn.client
+}
+
+func (conn *Conn) Context() context.Context {
+	return conn.context
+}
+
+func (conn *Conn) read() {
+	var (
+		message      json.RawMessage
+		messageError *Error
+		err          error
+		ok           bool
+	)
+
+	for {
+		message, err = conn.codec.ReadMessage()
+		if err != nil {
+			messageError, ok = err.(*Error)
+			if !ok {
+				break
+			}
+
+			err = conn.codec.WriteMessage(json.RawMessage(messageError.Response()))
+			if err != nil {
+				break
+			}
+
+			continue
+		}
+
+		conn.receive(message)
+	}
+
+	conn.shutdown(err)
+}
+
+func (conn *Conn) receive(message json.RawMessage) {
+	var (
+		messageBatch  bool
+		elementArray  []json.RawMessage
+		element       json.RawMessage
+		elementProbe  map[string]json.RawMessage
+		elementMethod string
+		requestArray  []json.RawMessage
+		responseSlice ResponseSlice
+		responseUnit  *ResponseUnit
+		err           error
+	)
+
+	message = bytes.TrimSpace(message)
+	messageBatch = len(message) > 0 && message[0] == '['
+
+	if messageBatch {
+		err = json.Unmarshal(message, &elementArray)
+		if err != nil {
+			conn.serve(message)
+			return
+		}
+	} else {
+		elementArray = []json.RawMessage{message}
+	}
+
+	for _, element = range elementArray {
+		elementProbe = nil
+
+		if json.Unmarshal(element, &elementProbe) == nil {
+			if elementProbe["method"] == nil && (elementProbe["result"] != nil || elementProbe["error"] != nil) {
+				responseUnit = &ResponseUnit{}
+				if responseUnit.SetResponseByte(element) == nil {
+					responseSlice = append(responseSlice, responseUnit)
+					continue
+				}
+			}
+
+			if elementProbe["method"] != nil && elementProbe["id"] == nil {
+				if json.Unmarshal(elementProbe["method"], &elementMethod) == nil {
+					if conn.receiveProgress(elementMethod, elementProbe["params"]) || conn.receiveSubscription(elementMethod, elementProbe["params"]) {
+						continue
+					}
+				}
+			}
+		}
+
+		requestArray = append(requestArray, element)
+	}
+
+	if len(responseSlice) > 0 {
+		conn.deliver(responseSlice)
+	}
+
+	switch {
+	case len(requestArray) == 0 && len(responseSlice) == 0:
+		conn.serve(message)
+	case len(requestArray) == 0:
+	case messageBatch:
+		message, err = json.Marshal(requestArray)
+		if err == nil {
+			conn.serve(message)
+		}
+	default:
+		conn.serve(requestArray[0])
+	}
+}
+
+func (conn *Conn) serve(message json.RawMessage) {
+	conn.waitGroup.Add(1)
+
+	go func() {
+		activation := &subscriptionActivation{mutex: make(chan interface{}, 1)}
+
+		defer func() {
+			activation.activate()
+			conn.waitGroup.Done()
+		}()
+
+		conn.server.serveMessage(context.WithValue(conn.context, subscriptionActivationKey{}, activation), conn.codec, message)
+	}()
+}
+
+func (conn *Conn) deliver(responseSlice ResponseSlice) {
+	var (
+		responseUnit *ResponseUnit
+		responseChan chan *ResponseUnit
+		responseKey  string
+	)
+
+	conn.mutex <- true
+	defer func() {
+		<-conn.mutex
+	}()
+
+	for _, responseUnit = range responseSlice {
+		if responseUnit.ID == nil {
+			continue
+		}
+
+		responseKey = idKey(responseUnit.ID)
+
+		responseChan = conn.pendingMap[responseKey]
+		if responseChan != nil {
+			delete(conn.pendingMap, responseKey)
+			responseChan <- responseUnit
+		}
+	}
+}
+
+func (conn *Conn) close(err error) {
+	conn.mutex <- true
+	defer func() {
+		<-conn.mutex
+	}()
+
+	if conn.closeError != nil {
+		return
+	}
+
+	if err == nil || err == io.EOF {
+		err = io.ErrClosedPipe
+	}
+
+	conn.closeError = err
+	close(conn.closeMutex)
+}
+
+func (conn *Conn) shutdown(err error) {
+	conn.mutex <- true
+	if conn.closeError != nil || err == io.EOF {
+		err = nil
+	}
+	<-conn.mutex
+
+	conn.close(err)
+	conn.closeSubscription(conn.closeError)
+
+	if err != nil {
+		conn.cancel()
+	}
+
+	conn.waitGroup.Wait()
+	conn.cancel()
+	conn.codec.Close()
+
+	conn.doneError = err
+	close(conn.doneMutex)
+}
+
+func (conn *Conn) Execute(requestSlice RequestSlice) (ResponseSlice, error) {
+	return conn.ExecuteContext(context.Background(), requestSlice)
+}
+
+func (conn *Conn) ExecuteContext(ctx context.Context, requestSlice RequestSlice) (responseSlice ResponseSlice, err error) {
+	var (
+		requestUnit      *RequestUnit
+		requestKey       string
+		requestKeyArray  []string
+		requestSliceJson []byte
+		responseChan     = make(chan *ResponseUnit, len(requestSlice))
+		responseUnit     *ResponseUnit
+	)
+
+	requestSliceJson, err = RequestMessage{RequestSlice: requestSlice, Batch: requestBatchFromContext(ctx, requestSlice)}.MarshalJSON()
+	if err != nil {
+		return
+	}
+
+	conn.mutex <- true
+
+	if conn.closeError != nil {
+		err = conn.closeError
+		<-conn.mutex
+		return
+	}
+
+	for _, requestUnit = range requestSlice {
+		if requestUnit.ID == nil {
+			continue
+		}
+
+		requestKey = idKey(requestUnit.ID)
+		if conn.pendingMap[requestKey] != nil {
+			err = fmt.Errorf("request id %s is already pending", requestKey)
+			break
+		}
+
+		conn.pendingMap[requestKey] = responseChan
+		requestKeyArray = append(requestKeyArray, requestKey)
+	}
+
+	<-conn.mutex
+
+	defer func() {
+		conn.mutex <- true
+		for _, requestKey = range requestKeyArray {
+			if conn.pendingMap[requestKey] == responseChan {
+				delete(conn.pendingMap, requestKey)
+			}
+		}
+		<-conn.mutex
+	}()
+
+	if err != nil {
+		return
+	}
+
+	err = conn.codec.WriteMessage(requestSliceJson)
+	if err != nil {
+		return
+	}
+
+	responseSlice = ResponseSlice{}
+
+	for range requestKeyArray {
+		select {
+		case responseUnit = <-responseChan:
+			responseSlice = append(responseSlice, responseUnit)
+		case <-conn.closeMutex:
+			return nil, conn.closeError
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return
+}
+
+func (conn *Conn) Notify(method string, params interface{}) (err error) {
+	var (
+		requestUnit = &RequestUnit{JsonRPC: "2.0", Method: method}
+		requestJson []byte
+	)
+
+	if params != nil {
+		requestUnit.Params, err = json.Marshal(params)
+		if err != nil {
+			return
+		}
+	}
+
+	requestJson, err = requestUnit.GetRequestByte()
+	if err != nil {
+		return
+	}
+
+	return conn.codec.WriteMessage(requestJson)
+}
+
+func (conn *Conn) Done() <-chan interface{} {
+	return conn.doneMutex
+}
+
+func (conn *Conn) Wait() error {
+	<-conn.doneMutex
+	return conn.doneError
+}
+
+func (conn *Conn) Close() error {
+	conn.close(nil)
+	return conn.codec.Close()
+}
+
+func NewConnContext(ctx context.Context, codec Codec, server *Server) *Conn {
+	if server == nil {
+		server = NewServer()
+	}
+
+	conn := &Conn{
+		codec:  codec,
+		server: server,
+
+		mutex:      make(chan interface{}, 1),
+		pendingMap: map[string]chan *ResponseUnit{},
+
+		inflightMap: map[string]*serverInflight{},
+		progressMap: map[string]ClientProgressFunc{},
+
+		subscriptionMap:       map[string]*Subscription{},
+		clientSubscriptionMap: map[string]*ClientSubscription{},
+		subscribeWaitMap:      map[string]int{},
+		subscribeOrphanMap:    map[string][]json.RawMessage{},
+
+		closeMutex: make(chan interface{}),
+		doneMutex:  make(chan interface{}),
+	}
+
+	conn.context, conn.cancel = context.WithCancel(context.WithValue(ctx, connContextKey{}, conn))
+	conn.client = NewClient(conn)
+	conn.client.SetIDGenerator(NewClientIDPrefix("conn-"))
+
+	go conn.read()
+
+	return conn
+}
+
+func NewConn(codec Codec, server *Server) *Conn {
+	return NewConnContext(context.Background(), codec, server)
+}
+
+//--------------------------------------------------------------------------------//
@@ -0,0 +1,65 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConnClientID(t *testing.T) {
+	var (
+		server        = NewServer()
+		pipeA, pipeB  = net.Pipe()
+		waitGroup     sync.WaitGroup
+		releaseMutex  = make(chan interface{})
+		progressArray = make([]int, 2)
+	)
+
+	err := server.Register("wait", func(ctx context.Context, value int) (int, error) {
+		ProgressFromContext(ctx).Report(value)
+		<-releaseMutex
+		return value, nil
+	}, WithParams("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connServer := NewConn(NewCodecHeader(pipeB), server)
+	defer connServer.Close()
+
+	connClient := NewConn(NewCodecHeader(pipeA), nil)
+	defer connClient.Close()
+
+	clientArray := []*Client{connClient.Client(), NewClient(connClient)}
+
+	for clientIndex, client := range clientArray {
+		waitGroup.Add(1)
+
+		go func(clientIndex int, client *Client) {
+			defer waitGroup.Done()
+
+			var result int
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			errorUnit := client.RequestProgress(ctx, "wait", map[string]int{"value": clientIndex + 1}, func(value json.RawMessage) {
+				json.Unmarshal(value, &progressArray[clientIndex])
+			}).Response(&result)
+			if errorUnit != nil || result != clientIndex+1 {
+				t.Errorf("client %d: expected %d, got %d, %v", clientIndex, clientIndex+1, result, errorUnit)
+			}
+		}(clientIndex, client)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(releaseMutex)
+	waitGroup.Wait()
+
+	if progressArray[0] != 1 || progressArray[1] != 2 {
+		t.Errorf("expected progress [1 2], got %v", progressArray)
+	}
+}
@@ -44,6 +44,10 @@ func NewErrorInternalError(errorData interface{}) (err *Error) {
 	return NewError(-32603, "Internal error", errorData)
 }
 
+func NewErrorRequestCancelled(errorData interface{}) (err *Error) {
+	return NewError(-32800, "Request cancelled", errorData)
+}
+
 func NewErrorServerError(errorCodePart int32, errorData interface{}) (err *Error) {
 	if errorCodePart < 0 {
 		errorCodePart = 0
@@ -57,3 +61,16 @@ func NewErrorServerError(errorCodePart int32, errorData interface{}) (err *Error
 }
 
 //--------------------------------------------------------------------------------//
+
+func newErrorTransport(err error) *Error {
+	switch errorType := err.(type) {
+	case *Error:
+		return errorType
+	case interface{ ErrorRPC() *Error }:
+		return errorType.ErrorRPC()
+	}
+
+	return NewErrorInternalError(err.Error())
+}
+
+//--------------------------------------------------------------------------------//
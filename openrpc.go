@@ -0,0 +1,304 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+)
+
+//--------------------------------------------------------------------------------//
+// OPENRPC
+//--------------------------------------------------------------------------------//
+
+const openRPCVersion = "1.2.6"
+
+type OpenRPC struct {
+	OpenRPC    string             `json:"openrpc"`
+	Info       OpenRPCInfo        `json:"info"`
+	Methods    []*OpenRPCMethod   `json:"methods"`
+	Components *OpenRPCComponents `json:"components,omitempty"`
+}
+
+type OpenRPCInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenRPCMethod struct {
+	Name           string                      `json:"name"`
+	Summary        string                      `json:"summary,omitempty"`
+	Description    string                      `json:"description,omitempty"`
+	Params         []*OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor   `json:"result,omitempty"`
+	ParamStructure string                      `json:"paramStructure,omitempty"`
+	Errors         []*Error                    `json:"errors,omitempty"`
+	Examples       []*OpenRPCExample           `json:"examples,omitempty"`
+}
+
+type OpenRPCContentDescriptor struct {
+	Name     string  `json:"name"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type OpenRPCExample struct {
+	Name   string                 `json:"name"`
+	Params []*OpenRPCExampleValue `json:"params"`
+	Result *OpenRPCExampleValue   `json:"result,omitempty"`
+}
+
+type OpenRPCExampleValue struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type OpenRPCComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+//--------------------------------------------------------------------------------//
+// OPENRPC OPTION
+//--------------------------------------------------------------------------------//
+
+type ServerHandlerInfo struct {
+	Summary     string
+	Description string
+	Examples    []ServerHandlerExample
+	Errors      []*Error
+}
+
+type ServerHandlerExample struct {
+	Name   string
+	Params interface{}
+	Result interface{}
+}
+
+func WithSummary(summary string) ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		handlerUnit.Info.Summary = summary
+	}
+}
+
+func WithDescription(description string) ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		handlerUnit.Info.Description = description
+	}
+}
+
+func WithExample(name string, params interface{}, result interface{}) ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		handlerUnit.Info.Examples = append(handlerUnit.Info.Examples, ServerHandlerExample{
+			Name:   name,
+			Params: params,
+			Result: result,
+		})
+	}
+}
+
+func WithErrors(errorArray ...*Error) ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		handlerUnit.Info.Errors = append(handlerUnit.Info.Errors, errorArray...)
+	}
+}
+
+//--------------------------------------------------------------------------------//
+// OPENRPC DISCOVER
+//--------------------------------------------------------------------------------//
+
+const openRPCDiscoverMethod = "rpc.discover"
+
+func (handler *ServerHandlerUnit) openRPCParams(generator *schemaGenerator) (paramArray []*OpenRPCContentDescriptor, paramStructure string) {
+	var (
+		requestSchema *Schema
+		fieldArray    []string
+		requiredMap   = map[string]bool{}
+		paramName     string
+	)
+
+	paramArray = []*OpenRPCContentDescriptor{}
+
+	if handler.Request == nil {
+		return
+	}
+
+	requestSchema = handler.ParamsSchema
+	if requestSchema == nil {
+		requestSchema = generator.generate(handler.Request)
+	}
+
+	if requestSchema.Ref != "" {
+		for name, definition := range generator.definitionMap {
+			if generator.refPrefix+name == requestSchema.Ref {
+				requestSchema = definition
+			}
+		}
+	}
+
+	fieldArray = paramFieldArray(handler.Request)
+	if fieldArray == nil {
+		paramName = "params"
+		if len(handler.Params) > 0 {
+			paramName = handler.Params[0].Name
+		}
+
+		paramArray = append(paramArray, &OpenRPCContentDescriptor{
+			Name:     paramName,
+			Required: len(handler.Params) == 0 || !handler.Params[0].Optional,
+			Schema:   requestSchema,
+		})
+
+		return paramArray, "by-position"
+	}
+
+	for _, fieldName := range requestSchema.Required {
+		requiredMap[fieldName] = true
+	}
+
+	for fieldIndex, fieldName := range fieldArray {
+		descriptor := &OpenRPCContentDescriptor{
+			Name:     fieldName,
+			Required: requiredMap[fieldName],
+			Schema:   requestSchema.Properties[fieldName],
+		}
+
+		if fieldIndex < len(handler.Params) {
+			descriptor.Name = handler.Params[fieldIndex].Name
+
+			if paramSchema := requestSchema.Properties[descriptor.Name]; paramSchema != nil {
+				descriptor.Schema = paramSchema
+				descriptor.Required = requiredMap[descriptor.Name]
+			}
+
+			descriptor.Required = descriptor.Required && !handler.Params[fieldIndex].Optional
+		}
+
+		if descriptor.Schema == nil {
+			descriptor.Schema = &Schema{}
+		}
+
+		paramArray = append(paramArray, descriptor)
+	}
+
+	return paramArray, "either"
+}
+
+func newOpenRPCExampleValue(name string, value interface{}) (exampleValue *OpenRPCExampleValue) {
+	exampleValue = &OpenRPCExampleValue{Name: name, Value: json.RawMessage("null")}
+
+	if valueJson, err := json.Marshal(value); err == nil {
+		exampleValue.Value = valueJson
+	}
+
+	return
+}
+
+func newOpenRPCExampleParams(descriptorArray []*OpenRPCContentDescriptor, params interface{}) (valueArray []*OpenRPCExampleValue) {
+	var (
+		paramsJson []byte
+		paramArray []json.RawMessage
+		paramMap   map[string]json.RawMessage
+		err        error
+	)
+
+	valueArray = []*OpenRPCExampleValue{}
+
+	if params == nil {
+		return
+	}
+
+	paramsJson, err = json.Marshal(params)
+	if err != nil {
+		return
+	}
+
+	switch {
+	case json.Unmarshal(paramsJson, &paramArray) == nil && len(paramArray) <= len(descriptorArray) && len(descriptorArray) > 1:
+		for paramIndex, paramJson := range paramArray {
+			valueArray = append(valueArray, &OpenRPCExampleValue{Name: descriptorArray[paramIndex].Name, Value: paramJson})
+		}
+	case json.Unmarshal(paramsJson, &paramMap) == nil && len(descriptorArray) > 1:
+		for _, descriptor := range descriptorArray {
+			if paramJson, ok := paramMap[descriptor.Name]; ok {
+				valueArray = append(valueArray, &OpenRPCExampleValue{Name: descriptor.Name, Value: paramJson})
+			}
+		}
+	case len(descriptorArray) == 1:
+		valueArray = append(valueArray, &OpenRPCExampleValue{Name: descriptorArray[0].Name, Value: paramsJson})
+	default:
+		valueArray = append(valueArray, &OpenRPCExampleValue{Name: "params", Value: paramsJson})
+	}
+
+	return
+}
+
+func (server *Server) OpenRPC(info OpenRPCInfo) (document *OpenRPC) {
+	var (
+		generator   = newSchemaGenerator("#/components/schemas/")
+		methodArray []string
+		handlerUnit ServerHandlerUnit
+	)
+
+	document = &OpenRPC{
+		OpenRPC: openRPCVersion,
+		Info:    info,
+		Methods: []*OpenRPCMethod{},
+	}
+
+	for method := range server.handlerMap {
+		if method != openRPCDiscoverMethod {
+			methodArray = append(methodArray, method)
+		}
+	}
+
+	sort.Strings(methodArray)
+
+	for _, method := range methodArray {
+		handlerUnit = server.handlerMap[method]
+
+		documentMethod := &OpenRPCMethod{
+			Name:        method,
+			Summary:     handlerUnit.Info.Summary,
+			Description: handlerUnit.Info.Description,
+			Result: &OpenRPCContentDescriptor{
+				Name:   "result",
+				Schema: handlerUnit.ResultSchema,
+			},
+			Errors: handlerUnit.Info.Errors,
+		}
+
+		if documentMethod.Result.Schema == nil {
+			documentMethod.Result.Schema = generator.generate(handlerUnit.Response)
+		}
+
+		documentMethod.Params, documentMethod.ParamStructure = handlerUnit.openRPCParams(generator)
+
+		for _, example := range handlerUnit.Info.Examples {
+			documentExample := &OpenRPCExample{
+				Name:   example.Name,
+				Params: []*OpenRPCExampleValue{},
+				Result: newOpenRPCExampleValue("result", example.Result),
+			}
+
+			documentExample.Params = newOpenRPCExampleParams(documentMethod.Params, example.Params)
+
+			documentMethod.Examples = append(documentMethod.Examples, documentExample)
+		}
+
+		document.Methods = append(document.Methods, documentMethod)
+	}
+
+	if len(generator.definitionMap) > 0 {
+		document.Components = &OpenRPCComponents{Schemas: generator.definitionMap}
+	}
+
+	return
+}
+
+func (server *Server) HandleDiscover(info OpenRPCInfo) {
+	server.HandleContextFunc(openRPCDiscoverMethod, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.OpenRPC(info), nil
+	}, nil, nil)
+}
+
+//--------------------------------------------------------------------------------//
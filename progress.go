@@ -0,0 +1,195 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+//--------------------------------------------------------------------------------//
+// PROGRESS
+//--------------------------------------------------------------------------------//
+
+type progressParams struct {
+	Token interface{}     `json:"token"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (params *progressParams) UnmarshalJSON(input []byte) (err error) {
+	var paramsRaw struct {
+		Token json.RawMessage `json:"token"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	err = json.Unmarshal(input, &paramsRaw)
+	if err != nil {
+		return
+	}
+
+	params.Value = paramsRaw.Value
+	params.Token, err = decodeID(paramsRaw.Token)
+
+	return
+}
+
+type progressContextKey struct{}
+
+//--------------------------------------------------------------------------------//
+// SERVER PROGRESS
+//--------------------------------------------------------------------------------//
+
+type Progress struct {
+	Token interface{}
+
+	method string
+	conn   *Conn
+}
+
+func (progress *Progress) Report(value interface{}) (err error) {
+	var valueJson json.RawMessage
+
+	if progress == nil {
+		return
+	}
+
+	valueJson, err = json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	return progress.conn.Notify(progress.method, &progressParams{
+		Token: progress.Token,
+		Value: valueJson,
+	})
+}
+
+func ProgressFromContext(ctx context.Context) *Progress {
+	progress, _ := ctx.Value(progressContextKey{}).(*Progress)
+	return progress
+}
+
+func (server *Server) SetProgress(progressMethod string, progressTokenField string) {
+	server.progressMethod = progressMethod
+	server.progressTokenField = progressTokenField
+}
+
+func (server *Server) withProgress(ctx context.Context, conn *Conn, requestUnit *RequestUnit) context.Context {
+	var (
+		paramMap   map[string]json.RawMessage
+		paramToken interface{}
+	)
+
+	if server.progressMethod == "" || server.progressTokenField == "" || requestUnit.Params == nil {
+		return ctx
+	}
+
+	if json.Unmarshal(requestUnit.Params, &paramMap) != nil || paramMap[server.progressTokenField] == nil {
+		return ctx
+	}
+
+	paramToken, _ = decodeID(paramMap[server.progressTokenField])
+	if paramToken == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, progressContextKey{}, &Progress{
+		Token: paramToken,
+
+		method: server.progressMethod,
+		conn:   conn,
+	})
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT PROGRESS
+//--------------------------------------------------------------------------------//
+
+type ClientProgressFunc func(value json.RawMessage)
+
+func (client *Client) SetProgress(progressMethod string, progressTokenField string) {
+	client.mutex <- true
+	client.progressMethod = progressMethod
+	client.progressTokenField = progressTokenField
+	<-client.mutex
+}
+
+func (client *Client) progress(executeID interface{}, option json.RawMessage, progressFunc ClientProgressFunc) (json.RawMessage, func(), *Error) {
+	var (
+		conn      *Conn
+		optionMap = map[string]json.RawMessage{}
+		token     = fmt.Sprintf("progress-%v", executeID)
+		tokenKey  string
+		tokenJson json.RawMessage
+		ok        bool
+		err       error
+	)
+
+	conn, ok = client.transport.(*Conn)
+	if !ok {
+		return nil, nil, NewErrorInvalidRequest("progress requires a duplex transport")
+	}
+
+	if client.progressMethod == "" || client.progressTokenField == "" {
+		return nil, nil, NewErrorInvalidRequest("progress is disabled")
+	}
+
+	if option != nil && string(option) != "null" {
+		err = json.Unmarshal(option, &optionMap)
+		if err != nil {
+			return nil, nil, NewErrorInvalidParams("progress requires object params")
+		}
+	}
+
+	tokenJson, _ = json.Marshal(token)
+	optionMap[client.progressTokenField] = tokenJson
+
+	option, err = json.Marshal(optionMap)
+	if err != nil {
+		return nil, nil, NewErrorInvalidParams(err.Error())
+	}
+
+	tokenKey = client.progressMethod + " " + idKey(token)
+
+	conn.mutex <- true
+	conn.progressMap[tokenKey] = progressFunc
+	<-conn.mutex
+
+	return option, func() {
+		conn.mutex <- true
+		delete(conn.progressMap, tokenKey)
+		<-conn.mutex
+	}, nil
+}
+
+func (client *Client) RequestProgress(ctx context.Context, method string, option interface{}, progressFunc ClientProgressFunc) clientExecuteRequest {
+	executeUnit := client.executeContext(ctx, true, method, option, progressFunc)
+
+	go client.execute()
+
+	return executeUnit
+}
+
+func (conn *Conn) receiveProgress(method string, params json.RawMessage) bool {
+	var (
+		notificationParams progressParams
+		progressFunc       ClientProgressFunc
+	)
+
+	if json.Unmarshal(params, &notificationParams) != nil || notificationParams.Token == nil {
+		return false
+	}
+
+	conn.mutex <- true
+	progressFunc = conn.progressMap[method+" "+idKey(notificationParams.Token)]
+	<-conn.mutex
+
+	if progressFunc == nil {
+		return false
+	}
+
+	progressFunc(notificationParams.Value)
+
+	return true
+}
+
+//--------------------------------------------------------------------------------//
@@ -0,0 +1,634 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//--------------------------------------------------------------------------------//
+// SCHEMA
+//--------------------------------------------------------------------------------//
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+var (
+	reflectTypeTime       = reflect.TypeOf(time.Time{})
+	reflectTypeRawMessage = reflect.TypeOf(json.RawMessage{})
+	reflectTypeNumber     = reflect.TypeOf(json.Number(""))
+	reflectTypeMarshaler  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+type schemaGenerator struct {
+	refPrefix     string
+	definitionMap map[string]*Schema
+	visitMap      map[reflect.Type]bool
+	recursiveMap  map[reflect.Type]bool
+}
+
+func newSchemaGenerator(refPrefix string) *schemaGenerator {
+	return &schemaGenerator{
+		refPrefix:     refPrefix,
+		definitionMap: map[string]*Schema{},
+		visitMap:      map[reflect.Type]bool{},
+		recursiveMap:  map[reflect.Type]bool{},
+	}
+}
+
+func (generator *schemaGenerator) generate(schemaType reflect.Type) (schema *Schema) {
+	if schemaType == nil {
+		return &Schema{}
+	}
+
+	for schemaType.Kind() == reflect.Ptr {
+		schemaType = schemaType.Elem()
+	}
+
+	switch schemaType {
+	case reflectTypeTime:
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflectTypeRawMessage:
+		return &Schema{}
+	case reflectTypeNumber:
+		return &Schema{Type: "number"}
+	}
+
+	if schemaType.Implements(reflectTypeMarshaler) || reflect.PtrTo(schemaType).Implements(reflectTypeMarshaler) {
+		return &Schema{}
+	}
+
+	switch schemaType.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if schemaType.Elem().Kind() == reflect.Uint8 && schemaType.Kind() == reflect.Slice {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: generator.generate(schemaType.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: generator.generate(schemaType.Elem())}
+	case reflect.Struct:
+		return generator.generateStruct(schemaType)
+	}
+
+	return &Schema{}
+}
+
+func (generator *schemaGenerator) generateStruct(schemaType reflect.Type) (schema *Schema) {
+	if schemaType.Name() != "" {
+		if generator.visitMap[schemaType] {
+			generator.recursiveMap[schemaType] = true
+			return &Schema{Ref: generator.refPrefix + schemaType.Name()}
+		}
+
+		generator.visitMap[schemaType] = true
+		defer delete(generator.visitMap, schemaType)
+	}
+
+	schema = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+
+	generator.generateField(schema, schemaType)
+
+	if generator.recursiveMap[schemaType] {
+		generator.definitionMap[schemaType.Name()] = schema
+		return &Schema{Ref: generator.refPrefix + schemaType.Name()}
+	}
+
+	return
+}
+
+func (generator *schemaGenerator) generateField(schema *Schema, schemaType reflect.Type) {
+	var (
+		structField reflect.StructField
+		fieldName   string
+		fieldOption string
+		fieldType   reflect.Type
+	)
+
+	for fieldIndex := 0; fieldIndex < schemaType.NumField(); fieldIndex++ {
+		structField = schemaType.Field(fieldIndex)
+		fieldType = structField.Type
+		fieldName, fieldOption = structField.Name, ""
+
+		if fieldTag, ok := structField.Tag.Lookup("json"); ok {
+			if fieldTag == "-" {
+				continue
+			}
+
+			if tagIndex := strings.Index(fieldTag, ","); tagIndex >= 0 {
+				fieldTag, fieldOption = fieldTag[:tagIndex], fieldTag[tagIndex:]
+			}
+
+			if fieldTag != "" {
+				fieldName = fieldTag
+			} else if structField.Anonymous {
+				fieldName = ""
+			}
+		} else if structField.Anonymous {
+			fieldName = ""
+		}
+
+		if fieldName == "" {
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct {
+				generator.generateField(schema, fieldType)
+				continue
+			}
+
+			fieldName = structField.Name
+		}
+
+		if structField.PkgPath != "" {
+			continue
+		}
+
+		schema.Properties[fieldName] = generator.generate(fieldType)
+
+		fieldRequired := !strings.Contains(fieldOption, ",omitempty") && fieldType.Kind() != reflect.Ptr
+		if schemaTag, ok := structField.Tag.Lookup("jsonschema"); ok {
+			fieldRequired = schema.Properties[fieldName].applyTag(schemaTag, fieldRequired)
+		}
+
+		if fieldRequired {
+			schema.Required = append(schema.Required, fieldName)
+		}
+	}
+}
+
+func (schema *Schema) applyTag(schemaTag string, required bool) bool {
+	for _, tagItem := range strings.Split(schemaTag, ",") {
+		tagKey, tagValue := tagItem, ""
+		if tagIndex := strings.Index(tagItem, "="); tagIndex >= 0 {
+			tagKey, tagValue = tagItem[:tagIndex], tagItem[tagIndex+1:]
+		}
+
+		switch strings.TrimSpace(tagKey) {
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "description":
+			schema.Description = tagValue
+		case "format":
+			schema.Format = tagValue
+		case "pattern":
+			schema.Pattern = tagValue
+		case "minimum":
+			schema.Minimum = parseSchemaFloat(tagValue)
+		case "maximum":
+			schema.Maximum = parseSchemaFloat(tagValue)
+		case "exclusiveMinimum":
+			schema.ExclusiveMinimum = parseSchemaFloat(tagValue)
+		case "exclusiveMaximum":
+			schema.ExclusiveMaximum = parseSchemaFloat(tagValue)
+		case "minLength":
+			schema.MinLength = parseSchemaInt(tagValue)
+		case "maxLength":
+			schema.MaxLength = parseSchemaInt(tagValue)
+		case "minItems":
+			schema.MinItems = parseSchemaInt(tagValue)
+		case "maxItems":
+			schema.MaxItems = parseSchemaInt(tagValue)
+		case "enum":
+			for _, enumValue := range strings.Split(tagValue, "|") {
+				var enumInterface interface{} = enumValue
+
+				if schema.Type != "string" {
+					if enumDecoded, err := decodeID(json.RawMessage(enumValue)); err == nil && enumDecoded != nil {
+						enumInterface = enumDecoded
+					}
+				}
+
+				schema.Enum = append(schema.Enum, enumInterface)
+			}
+		}
+	}
+
+	return required
+}
+
+func parseSchemaFloat(input string) *float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+	if err != nil {
+		return nil
+	}
+
+	return &value
+}
+
+func parseSchemaInt(input string) *int {
+	value, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return nil
+	}
+
+	return &value
+}
+
+func NewSchema(schemaType reflect.Type) (schema *Schema) {
+	generator := newSchemaGenerator("#/definitions/")
+
+	schema = generator.generate(schemaType)
+	if len(generator.definitionMap) > 0 {
+		schema.Definitions = generator.definitionMap
+	}
+
+	return
+}
+
+//--------------------------------------------------------------------------------//
+// SCHEMA VALIDATE
+//--------------------------------------------------------------------------------//
+
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type schemaValidator struct {
+	root           *Schema
+	violationArray []SchemaViolation
+}
+
+func (validator *schemaValidator) violate(path string, format string, argumentArray ...interface{}) {
+	validator.violationArray = append(validator.violationArray, SchemaViolation{
+		Path:    path,
+		Message: fmt.Sprintf(format, argumentArray...),
+	})
+}
+
+func (validator *schemaValidator) resolve(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 32; depth++ {
+		name := schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+
+		if validator.root.Definitions == nil || validator.root.Definitions[name] == nil {
+			return nil
+		}
+
+		schema = validator.root.Definitions[name]
+	}
+
+	return schema
+}
+
+func schemaTypeOf(value interface{}) string {
+	switch valueType := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if validID(valueType) {
+			return "integer"
+		}
+
+		if valueFloat, err := valueType.Float64(); err == nil && valueFloat == math.Trunc(valueFloat) {
+			return "integer"
+		}
+
+		return "number"
+	}
+
+	return ""
+}
+
+func schemaEqual(left interface{}, right interface{}) bool {
+	leftNumber, leftOk := left.(json.Number)
+	rightNumber, rightOk := right.(json.Number)
+
+	if leftOk && rightOk {
+		leftFloat, leftErr := leftNumber.Float64()
+		rightFloat, rightErr := rightNumber.Float64()
+
+		return leftErr == nil && rightErr == nil && leftFloat == rightFloat
+	}
+
+	leftJson, leftErr := json.Marshal(left)
+	rightJson, rightErr := json.Marshal(right)
+
+	return leftErr == nil && rightErr == nil && bytes.Equal(leftJson, rightJson)
+}
+
+func schemaContains(nameArray []string, name string) bool {
+	for _, arrayName := range nameArray {
+		if arrayName == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (validator *schemaValidator) validate(schema *Schema, path string, value interface{}) {
+	var valueType string
+
+	schema = validator.resolve(schema)
+	if schema == nil {
+		validator.violate(path, "unresolved schema reference")
+		return
+	}
+
+	valueType = schemaTypeOf(value)
+
+	switch {
+	case schema.Type == "":
+	case schema.Type == valueType:
+	case schema.Type == "number" && valueType == "integer":
+	default:
+		validator.violate(path, "expected %s, got %s", schema.Type, valueType)
+		return
+	}
+
+	if len(schema.Enum) > 0 {
+		enumMatch := false
+		for _, enumValue := range schema.Enum {
+			enumMatch = enumMatch || schemaEqual(value, enumValue)
+		}
+
+		if !enumMatch {
+			validator.violate(path, "value is not one of the allowed values")
+		}
+	}
+
+	switch valueType := value.(type) {
+	case json.Number:
+		valueFloat, _ := valueType.Float64()
+
+		if schema.Minimum != nil && valueFloat < *schema.Minimum {
+			validator.violate(path, "must be >= %v", *schema.Minimum)
+		}
+
+		if schema.Maximum != nil && valueFloat > *schema.Maximum {
+			validator.violate(path, "must be <= %v", *schema.Maximum)
+		}
+
+		if schema.ExclusiveMinimum != nil && valueFloat <= *schema.ExclusiveMinimum {
+			validator.violate(path, "must be > %v", *schema.ExclusiveMinimum)
+		}
+
+		if schema.ExclusiveMaximum != nil && valueFloat >= *schema.ExclusiveMaximum {
+			validator.violate(path, "must be < %v", *schema.ExclusiveMaximum)
+		}
+	case string:
+		valueLength := utf8.RuneCountInString(valueType)
+
+		if schema.MinLength != nil && valueLength < *schema.MinLength {
+			validator.violate(path, "length must be >= %d", *schema.MinLength)
+		}
+
+		if schema.MaxLength != nil && valueLength > *schema.MaxLength {
+			validator.violate(path, "length must be <= %d", *schema.MaxLength)
+		}
+
+		if schema.Pattern != "" {
+			if patternRegexp, err := regexp.Compile(schema.Pattern); err != nil {
+				validator.violate(path, "invalid pattern %q", schema.Pattern)
+			} else if !patternRegexp.MatchString(valueType) {
+				validator.violate(path, "must match pattern %q", schema.Pattern)
+			}
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(valueType) < *schema.MinItems {
+			validator.violate(path, "must contain at least %d items", *schema.MinItems)
+		}
+
+		if schema.MaxItems != nil && len(valueType) > *schema.MaxItems {
+			validator.violate(path, "must contain at most %d items", *schema.MaxItems)
+		}
+
+		if schema.Items != nil {
+			for itemIndex, itemValue := range valueType {
+				validator.validate(schema.Items, fmt.Sprintf("%s[%d]", path, itemIndex), itemValue)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := valueType[name]; !ok {
+				validator.violate(path+"."+name, "is required")
+			}
+		}
+
+		for name, propertyValue := range valueType {
+			if propertyValue == nil && !schemaContains(schema.Required, name) {
+				continue
+			}
+
+			if propertySchema := schema.Properties[name]; propertySchema != nil {
+				validator.validate(propertySchema, path+"."+name, propertyValue)
+			} else if schema.AdditionalProperties != nil {
+				validator.validate(schema.AdditionalProperties, path+"."+name, propertyValue)
+			}
+		}
+	}
+}
+
+func (schema *Schema) Validate(input json.RawMessage) []SchemaViolation {
+	var (
+		value     interface{}
+		validator = &schemaValidator{root: schema}
+	)
+
+	valueDecoder := json.NewDecoder(bytes.NewReader(input))
+	valueDecoder.UseNumber()
+
+	if err := valueDecoder.Decode(&value); err != nil {
+		validator.violate("$", "%s", err.Error())
+		return validator.violationArray
+	}
+
+	validator.validate(schema, "$", value)
+
+	sort.Slice(validator.violationArray, func(left int, right int) bool {
+		return validator.violationArray[left].Path < validator.violationArray[right].Path
+	})
+
+	return validator.violationArray
+}
+
+//--------------------------------------------------------------------------------//
+// SCHEMA OPTION
+//--------------------------------------------------------------------------------//
+
+func WithParamsSchema(schema *Schema) ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		handlerUnit.ParamsSchema = schema
+	}
+}
+
+func WithResultSchema(schema *Schema) ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		handlerUnit.ResultSchema = schema
+	}
+}
+
+func WithSchemaValidation() ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		handlerUnit.schemaDerive = true
+	}
+}
+
+func (handler *ServerHandlerUnit) deriveSchema() {
+	var paramSchema *Schema
+
+	if handler.ParamsSchema == nil && handler.Request != nil {
+		handler.ParamsSchema = NewSchema(handler.Request)
+
+		if fieldArray := paramFieldArray(handler.Request); fieldArray != nil && handler.ParamsSchema.Properties != nil {
+			for fieldIndex, param := range handler.Params {
+				if fieldIndex >= len(fieldArray) || param.Name == fieldArray[fieldIndex] {
+					continue
+				}
+
+				paramSchema = handler.ParamsSchema.Properties[fieldArray[fieldIndex]]
+				delete(handler.ParamsSchema.Properties, fieldArray[fieldIndex])
+				handler.ParamsSchema.Properties[param.Name] = paramSchema
+
+				for requiredIndex, name := range handler.ParamsSchema.Required {
+					if name == fieldArray[fieldIndex] {
+						handler.ParamsSchema.Required[requiredIndex] = param.Name
+					}
+				}
+			}
+		}
+	}
+
+	if handler.ResultSchema == nil && handler.Response != nil {
+		handler.ResultSchema = NewSchema(handler.Response)
+	}
+}
+
+func (handler *ServerHandlerUnit) paramsByName(params json.RawMessage) json.RawMessage {
+	var (
+		fieldArray = paramFieldArray(handler.Request)
+		paramMap   map[string]json.RawMessage
+		renamed    bool
+		paramsJson []byte
+		err        error
+	)
+
+	if fieldArray == nil || json.Unmarshal(params, &paramMap) != nil || paramMap == nil {
+		return params
+	}
+
+	for fieldIndex, param := range handler.Params {
+		if fieldIndex >= len(fieldArray) || param.Name == fieldArray[fieldIndex] {
+			continue
+		}
+
+		if paramJson, ok := paramMap[fieldArray[fieldIndex]]; ok {
+			delete(paramMap, fieldArray[fieldIndex])
+			paramMap[param.Name] = paramJson
+			renamed = true
+		}
+	}
+
+	if !renamed {
+		return params
+	}
+
+	paramsJson, err = json.Marshal(paramMap)
+	if err != nil {
+		return params
+	}
+
+	return paramsJson
+}
+
+func (handler *ServerHandlerUnit) validateParams(params json.RawMessage) *Error {
+	var violationArray []SchemaViolation
+
+	if handler.ParamsSchema == nil {
+		return nil
+	}
+
+	if len(bytes.TrimSpace(params)) == 0 {
+		switch handler.ParamsSchema.Type {
+		case "object":
+			params = json.RawMessage("{}")
+		case "array":
+			params = json.RawMessage("[]")
+		default:
+			return nil
+		}
+	}
+
+	violationArray = handler.ParamsSchema.Validate(handler.paramsByName(params))
+	if len(violationArray) > 0 {
+		return NewErrorInvalidParams(violationArray)
+	}
+
+	return nil
+}
+
+func (handler *ServerHandlerUnit) validateResult(ctx context.Context, result json.RawMessage) *Error {
+	var violationArray []SchemaViolation
+
+	if handler.ResultSchema == nil || result == nil {
+		return nil
+	}
+
+	if server := serverFromContext(ctx); server == nil || !server.debug {
+		return nil
+	}
+
+	violationArray = handler.ResultSchema.Validate(result)
+	if len(violationArray) > 0 {
+		return NewErrorInternalError(map[string]interface{}{
+			"message":    "result does not match schema",
+			"violations": violationArray,
+		})
+	}
+
+	return nil
+}
+
+//--------------------------------------------------------------------------------//
@@ -1,10 +1,13 @@
 package jsonrpc2
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
+	"runtime/debug"
+	"sync"
 )
 
 //--------------------------------------------------------------------------------//
@@ -13,14 +16,59 @@ import (
 
 type ServerHandlerFunc func(interface{}) (interface{}, error)
 
+func (handlerFunc ServerHandlerFunc) Context() ServerHandlerContextFunc {
+	if handlerFunc == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return handlerFunc(request)
+	}
+}
+
+type ServerHandlerContextFunc func(context.Context, interface{}) (interface{}, error)
+
 type ServerHandlerUnit struct {
-	Request  reflect.Type
-	Response reflect.Type
-	Function ServerHandlerFunc
+	Request         reflect.Type
+	Response        reflect.Type
+	Function        ServerHandlerFunc
+	ContextFunction ServerHandlerContextFunc
+	Params          []ServerHandlerParam
+	Info            ServerHandlerInfo
+	Sequential      bool
+
+	ParamsSchema *Schema
+	ResultSchema *Schema
+
+	schemaDerive bool
+}
+
+func (handler *ServerHandlerUnit) function() ServerHandlerContextFunc {
+	if handler.ContextFunction != nil {
+		return handler.ContextFunction
+	}
+
+	return handler.Function.Context()
+}
+
+func (handler *ServerHandlerUnit) call(ctx context.Context, requestUnit *RequestUnit, request interface{}) (response interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			response = nil
+			err = newErrorPanic(ctx, requestUnit, recovered, debug.Stack())
+		}
+	}()
+
+	return handler.function()(ctx, request)
 }
 
 func (handler *ServerHandlerUnit) Execute(requestUnit *RequestUnit) (responseUnit *ResponseUnit) {
+	return handler.ExecuteContext(context.Background(), requestUnit)
+}
+
+func (handler *ServerHandlerUnit) ExecuteContext(ctx context.Context, requestUnit *RequestUnit) (responseUnit *ResponseUnit) {
 	var (
+		requestParams         json.RawMessage
 		requestParamInterface interface{}
 		requestParamReflect   reflect.Value
 		responseResult        interface{}
@@ -34,11 +82,17 @@ func (handler *ServerHandlerUnit) Execute(requestUnit *RequestUnit) (responseUni
 		return
 	}
 
-	if handler.Function != nil {
-		if requestUnit.Params != nil {
+	if handler.function() != nil {
+		requestParams, responseError = handler.bindParams(requestUnit.Params)
+
+		if responseError == nil {
+			responseError = handler.validateParams(requestParams)
+		}
+
+		if requestParams != nil && responseError == nil {
 			if handler.Request != nil {
 				requestParamReflect = reflect.New(handler.Request).Elem()
-				err = json.Unmarshal(requestUnit.Params, requestParamReflect.Addr().Interface())
+				err = json.Unmarshal(requestParams, requestParamReflect.Addr().Interface())
 				if err == nil {
 					requestParamInterface = requestParamReflect.Interface()
 				} else {
@@ -48,7 +102,7 @@ func (handler *ServerHandlerUnit) Execute(requestUnit *RequestUnit) (responseUni
 		}
 
 		if responseError == nil {
-			responseResult, err = handler.Function(requestParamInterface)
+			responseResult, err = handler.call(ctx, requestUnit, requestParamInterface)
 			if responseResult == nil && err == nil {
 				err = fmt.Errorf("handler function return nothing")
 			}
@@ -68,11 +122,13 @@ func (handler *ServerHandlerUnit) Execute(requestUnit *RequestUnit) (responseUni
 		responseError = NewErrorMethodNotFound("handler function is nil")
 	}
 
-	if requestUnit.ID != nil && requestUnit.ID != false && requestUnit.ID != true {
+	if requestUnit.hasID() {
 		if responseResult != nil {
 			responseResultJson, err = json.Marshal(responseResult)
 			if err != nil {
 				responseError = NewErrorInternalError(err.Error())
+			} else if errorSchema := handler.validateResult(ctx, responseResultJson); errorSchema != nil {
+				responseResultJson, responseError = nil, errorSchema
 			}
 		}
 
@@ -87,41 +143,247 @@ func (handler *ServerHandlerUnit) Execute(requestUnit *RequestUnit) (responseUni
 	return
 }
 
+//--------------------------------------------------------------------------------//
+// SERVER PANIC
+//--------------------------------------------------------------------------------//
+
+type ServerPanicFunc func(ctx context.Context, requestUnit *RequestUnit, recovered interface{}, stack []byte)
+
+func newErrorPanic(ctx context.Context, requestUnit *RequestUnit, recovered interface{}, stack []byte) *Error {
+	server := serverFromContext(ctx)
+
+	if server == nil {
+		return NewErrorInternalError("handler panic")
+	}
+
+	if server.panicFunc != nil {
+		server.panicFunc(ctx, requestUnit, recovered, stack)
+	}
+
+	if server.debug {
+		return NewErrorInternalError(map[string]string{
+			"panic": fmt.Sprint(recovered),
+			"stack": string(stack),
+		})
+	}
+
+	return NewErrorInternalError("handler panic")
+}
+
+//--------------------------------------------------------------------------------//
+// SERVER HANDLER OPTION
+//--------------------------------------------------------------------------------//
+
+type ServerHandlerOption func(*ServerHandlerUnit)
+
+func WithSequential() ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		handlerUnit.Sequential = true
+	}
+}
+
 //--------------------------------------------------------------------------------//
 // SERVER
 //--------------------------------------------------------------------------------//
 
+type serverContextKey struct{}
+
+func serverFromContext(ctx context.Context) *Server {
+	server, _ := ctx.Value(serverContextKey{}).(*Server)
+	return server
+}
+
 type Server struct {
-	handlerMap map[string]ServerHandlerUnit
+	handlerMap      map[string]ServerHandlerUnit
+	subscriptionMap map[string]map[string]ServerSubscribeFunc
+
+	debug     bool
+	strict    bool
+	panicFunc ServerPanicFunc
+
+	concurrencyMutex chan interface{}
+
+	middlewareArray      []ServerMiddleware
+	batchMiddlewareArray []ServerBatchMiddleware
+
+	cancelMethod string
+
+	progressMethod     string
+	progressTokenField string
 }
 
-func (server *Server) HandleFunc(method string, handleFunc ServerHandlerFunc, request interface{}, response interface{}) {
-	var (
-		handlerRequest  reflect.Type = nil
-		handlerResponse reflect.Type = nil
-	)
+func (server *Server) SetCancelMethod(cancelMethod string) {
+	server.cancelMethod = cancelMethod
+}
+
+func (server *Server) SetDebug(debugMode bool) {
+	server.debug = debugMode
+}
+
+func (server *Server) SetStrict(strictMode bool) {
+	server.strict = strictMode
+}
+
+func (server *Server) SetPanicHandler(panicFunc ServerPanicFunc) {
+	server.panicFunc = panicFunc
+}
+
+func (server *Server) SetConcurrency(concurrencyLimit int) {
+	if concurrencyLimit > 1 {
+		server.concurrencyMutex = make(chan interface{}, concurrencyLimit)
+	} else {
+		server.concurrencyMutex = nil
+	}
+}
+
+func (handlerUnit *ServerHandlerUnit) prepare(optionArray []ServerHandlerOption) (err error) {
+	if handlerUnit.Params == nil && handlerUnit.Request != nil {
+		handlerUnit.Params = newServerHandlerParamArray(handlerUnit.Request)
+	}
+
+	for _, option := range optionArray {
+		option(handlerUnit)
+	}
+
+	err = handlerUnit.checkParams()
+	if err != nil {
+		return
+	}
+
+	if handlerUnit.schemaDerive {
+		handlerUnit.deriveSchema()
+	}
+
+	return
+}
+
+func (server *Server) handle(method string, handlerUnit ServerHandlerUnit, optionArray []ServerHandlerOption) (err error) {
+	err = handlerUnit.prepare(optionArray)
+	if err != nil {
+		return
+	}
 
+	server.handlerMap[method] = handlerUnit
+
+	return
+}
+
+func (server *Server) mustHandle(method string, handlerUnit ServerHandlerUnit, optionArray []ServerHandlerOption) {
+	if err := server.handle(method, handlerUnit, optionArray); err != nil {
+		panic(fmt.Sprintf(`jsonrpc2: handle "%s": %s`, method, err.Error()))
+	}
+}
+
+func newServerHandlerUnitType(request interface{}, response interface{}, handlerUnit ServerHandlerUnit) ServerHandlerUnit {
 	if request != nil {
-		handlerRequest = reflect.TypeOf(request)
+		handlerUnit.Request = reflect.TypeOf(request)
 	}
 
 	if response != nil {
-		handlerResponse = reflect.TypeOf(response)
+		handlerUnit.Response = reflect.TypeOf(response)
 	}
 
-	server.handlerMap[method] = ServerHandlerUnit{
-		Request:  handlerRequest,
-		Response: handlerResponse,
+	return handlerUnit
+}
+
+func (server *Server) HandleFunc(method string, handleFunc ServerHandlerFunc, request interface{}, response interface{}, optionArray ...ServerHandlerOption) {
+	server.mustHandle(method, newServerHandlerUnitType(request, response, ServerHandlerUnit{
 		Function: handleFunc,
+	}), optionArray)
+}
+
+func (server *Server) HandleContextFunc(method string, handleFunc ServerHandlerContextFunc, request interface{}, response interface{}, optionArray ...ServerHandlerOption) {
+	server.mustHandle(method, newServerHandlerUnitType(request, response, ServerHandlerUnit{
+		ContextFunction: handleFunc,
+	}), optionArray)
+}
+
+func (server *Server) executeUnit(ctx context.Context, requestUnit *RequestUnit) (responseUnit *ResponseUnit) {
+	var (
+		handlerUnit ServerHandlerUnit
+		ok          bool
+	)
+
+	if requestUnit.invalid != nil {
+		return &ResponseUnit{JsonRPC: "2.0", Error: requestUnit.invalid}
+	}
+
+	if server.strict {
+		if errorValidate := requestUnit.Validate(); errorValidate != nil {
+			responseUnit = &ResponseUnit{JsonRPC: "2.0", Error: errorValidate}
+			if validID(requestUnit.ID) {
+				responseUnit.ID = requestUnit.ID
+			}
+
+			return
+		}
+	}
+
+	if requestUnit.JsonRPC != "2.0" {
+		return &ResponseUnit{JsonRPC: "2.0", Error: NewErrorInvalidRequest(nil)}
 	}
+
+	if server.cancelMethod != "" && requestUnit.Method == server.cancelMethod {
+		return server.executeCancel(ctx, requestUnit)
+	}
+
+	handlerUnit, ok = server.handlerMap[requestUnit.Method]
+	if ok {
+		responseUnit = server.executeHandler(ctx, handlerUnit, requestUnit)
+	}
+
+	if requestUnit.hasID() {
+		if !ok {
+			responseUnit = &ResponseUnit{JsonRPC: "2.0", ID: requestUnit.ID, Error: NewErrorMethodNotFound(fmt.Sprintf(`handler "%s" not founded`, requestUnit.Method))}
+		} else if responseUnit == nil {
+			responseUnit = &ResponseUnit{JsonRPC: "2.0", ID: requestUnit.ID, Error: NewErrorInternalError("response is nil")}
+		}
+	}
+
+	return
+}
+
+func (server *Server) executeUnitConcurrent(ctx context.Context, concurrencyMutex chan interface{}, requestUnit *RequestUnit) (responseUnit *ResponseUnit) {
+	concurrencyMutex <- true
+	defer func() {
+		<-concurrencyMutex
+	}()
+
+	return server.dispatchUnit(ctx, requestUnit)
 }
 
 func (server *Server) Execute(requestSlice RequestSlice) (responseSlice ResponseSlice) {
+	return server.ExecuteContext(context.Background(), requestSlice)
+}
+
+func (server *Server) ExecuteContext(ctx context.Context, requestSlice RequestSlice) (responseSlice ResponseSlice) {
+	if requestSlice == nil {
+		return
+	}
+
+	if serverFromContext(ctx) != server {
+		ctx = context.WithValue(ctx, serverContextKey{}, server)
+	}
+
+	return server.dispatchSlice(ctx, requestSlice)
+}
+
+func (server *Server) ExecuteMessage(ctx context.Context, requestMessage RequestMessage) (responseMessage ResponseMessage) {
+	responseMessage.ResponseSlice = server.ExecuteContext(ctx, requestMessage.RequestSlice)
+	responseMessage.Batch = requestMessage.Batch
+
+	return
+}
+
+func (server *Server) executeSlice(ctx context.Context, requestSlice RequestSlice) (responseSlice ResponseSlice) {
 	var (
-		requestUnit  *RequestUnit
-		handlerUnit  ServerHandlerUnit
-		responseUnit *ResponseUnit
-		ok           bool
+		requestIndex       int
+		requestUnit        *RequestUnit
+		responseUnit       *ResponseUnit
+		responseArray      []*ResponseUnit
+		sequentialArray    []int
+		concurrencyMutex   = server.concurrencyMutex
+		concurrencyWaiting sync.WaitGroup
 	)
 
 	if requestSlice == nil {
@@ -129,51 +391,95 @@ func (server *Server) Execute(requestSlice RequestSlice) (responseSlice Response
 	}
 
 	responseSlice = ResponseSlice{}
+	responseArray = make([]*ResponseUnit, len(requestSlice))
 
-	for _, requestUnit = range requestSlice {
-		if requestUnit.JsonRPC == "2.0" {
-			handlerUnit, ok = server.handlerMap[requestUnit.Method]
-			if ok {
-				responseUnit = handlerUnit.Execute(requestUnit)
+	if concurrencyMutex == nil || len(requestSlice) < 2 {
+		for requestIndex, requestUnit = range requestSlice {
+			responseArray[requestIndex] = server.dispatchUnit(ctx, requestUnit)
+		}
+	} else {
+		for requestIndex, requestUnit = range requestSlice {
+			if server.handlerMap[requestUnit.Method].Sequential {
+				sequentialArray = append(sequentialArray, requestIndex)
+				continue
 			}
-		} else {
-			responseSlice = append(responseSlice, &ResponseUnit{JsonRPC: "2.0", Error: NewErrorInvalidRequest(nil)})
+
+			concurrencyWaiting.Add(1)
+			go func(requestIndex int, requestUnit *RequestUnit) {
+				defer concurrencyWaiting.Done()
+				responseArray[requestIndex] = server.executeUnitConcurrent(ctx, concurrencyMutex, requestUnit)
+			}(requestIndex, requestUnit)
 		}
 
-		if requestUnit.ID != nil && requestUnit.ID != false && requestUnit.ID != true {
-			if ok {
-				if responseUnit != nil {
-					responseSlice = append(responseSlice, responseUnit)
-				} else {
-					responseSlice = append(responseSlice, &ResponseUnit{JsonRPC: "2.0", ID: requestUnit.ID, Error: NewErrorInternalError("response is nil")})
+		if len(sequentialArray) > 0 {
+			concurrencyWaiting.Add(1)
+			go func() {
+				defer concurrencyWaiting.Done()
+				for _, requestIndex := range sequentialArray {
+					responseArray[requestIndex] = server.executeUnitConcurrent(ctx, concurrencyMutex, requestSlice[requestIndex])
 				}
-			} else {
-				responseSlice = append(responseSlice, &ResponseUnit{JsonRPC: "2.0", ID: requestUnit.ID, Error: NewErrorMethodNotFound(fmt.Sprintf(`handler "%s" not founded`, requestUnit.Method))})
-			}
+			}()
+		}
+
+		concurrencyWaiting.Wait()
+	}
+
+	for _, responseUnit = range responseArray {
+		if responseUnit != nil {
+			responseSlice = append(responseSlice, responseUnit)
 		}
 	}
 
 	return
 }
 
+func (server *Server) serveMessage(ctx context.Context, codec Codec, message json.RawMessage) (err error) {
+	var (
+		requestMessage  RequestMessage
+		responseMessage ResponseMessage
+		responseJson    []byte
+	)
+
+	requestMessage, err = NewRequestMessage([]byte(message))
+	if err != nil {
+		return codec.WriteMessage(json.RawMessage(NewErrorParseError(err.Error()).Response()))
+	}
+
+	if len(requestMessage.RequestSlice) == 0 {
+		return codec.WriteMessage(json.RawMessage(NewErrorInvalidRequest("request is empty").Response()))
+	}
+
+	responseMessage = server.ExecuteMessage(ctx, requestMessage)
+	if len(responseMessage.ResponseSlice) == 0 {
+		return
+	}
+
+	responseJson, err = responseMessage.MarshalJSON()
+	if err != nil {
+		return codec.WriteMessage(json.RawMessage(NewErrorInternalError(err.Error()).Response()))
+	}
+
+	return codec.WriteMessage(responseJson)
+}
+
 func (server *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	if r.Method == "POST" {
-		requestSlice, err := NewRequestSlice(r.Body)
+		requestMessage, err := NewRequestMessage(r.Body)
 
 		if err != nil {
 			http.Error(rw, NewErrorParseError(err.Error()).Response(), http.StatusOK)
 			return
 		}
 
-		if len(requestSlice) == 0 {
+		if len(requestMessage.RequestSlice) == 0 {
 			http.Error(rw, NewErrorInvalidRequest("request is empty").Response(), http.StatusOK)
 			return
 		}
 
-		responseSlice := server.Execute(requestSlice)
+		responseMessage := server.ExecuteMessage(r.Context(), requestMessage)
 
-		if len(responseSlice) > 0 {
-			jsonData, err := json.Marshal(responseSlice)
+		if len(responseMessage.ResponseSlice) > 0 {
+			jsonData, err := json.Marshal(responseMessage)
 
 			if err != nil {
 				http.Error(rw, NewErrorInternalError(err.Error()).Response(), http.StatusOK)
@@ -187,7 +493,13 @@ func (server *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 
 func NewServer() *Server {
 	return &Server{
-		handlerMap: map[string]ServerHandlerUnit{},
+		handlerMap:      map[string]ServerHandlerUnit{},
+		subscriptionMap: map[string]map[string]ServerSubscribeFunc{},
+
+		cancelMethod: "$/cancelRequest",
+
+		progressMethod:     "$/progress",
+		progressTokenField: "progressToken",
 	}
 }
 
@@ -0,0 +1,103 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+)
+
+//--------------------------------------------------------------------------------//
+// SERVER CANCEL
+//--------------------------------------------------------------------------------//
+
+type serverInflight struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+func (server *Server) executeHandler(ctx context.Context, handlerUnit ServerHandlerUnit, requestUnit *RequestUnit) (responseUnit *ResponseUnit) {
+	var (
+		conn        = ConnFromContext(ctx)
+		inflight    *serverInflight
+		inflightKey string
+	)
+
+	if conn == nil {
+		return handlerUnit.ExecuteContext(ctx, requestUnit)
+	}
+
+	ctx = server.withProgress(ctx, conn, requestUnit)
+
+	if !requestUnit.hasID() {
+		return handlerUnit.ExecuteContext(ctx, requestUnit)
+	}
+
+	inflight = &serverInflight{}
+	inflightKey = idKey(requestUnit.ID)
+
+	ctx, inflight.cancel = context.WithCancel(ctx)
+
+	conn.mutex <- true
+	conn.inflightMap[inflightKey] = inflight
+	<-conn.mutex
+
+	defer func() {
+		conn.mutex <- true
+		if conn.inflightMap[inflightKey] == inflight {
+			delete(conn.inflightMap, inflightKey)
+		}
+		<-conn.mutex
+
+		inflight.cancel()
+	}()
+
+	responseUnit = handlerUnit.ExecuteContext(ctx, requestUnit)
+
+	conn.mutex <- true
+	if inflight.cancelled {
+		responseUnit = &ResponseUnit{JsonRPC: "2.0", ID: requestUnit.ID, Error: NewErrorRequestCancelled(nil)}
+	}
+	<-conn.mutex
+
+	return
+}
+
+func (server *Server) executeCancel(ctx context.Context, requestUnit *RequestUnit) (responseUnit *ResponseUnit) {
+	var (
+		conn         = ConnFromContext(ctx)
+		cancelParams struct {
+			ID json.RawMessage `json:"id"`
+		}
+		cancelID interface{}
+		inflight *serverInflight
+	)
+
+	if requestUnit.Params != nil && json.Unmarshal(requestUnit.Params, &cancelParams) == nil {
+		cancelID, _ = decodeID(cancelParams.ID)
+	}
+
+	if cancelID == nil {
+		if requestUnit.hasID() {
+			responseUnit = &ResponseUnit{JsonRPC: "2.0", ID: requestUnit.ID, Error: NewErrorInvalidParams(`expected {"id": ...}`)}
+		}
+
+		return
+	}
+
+	if conn != nil {
+		conn.mutex <- true
+		inflight = conn.inflightMap[idKey(cancelID)]
+		if inflight != nil {
+			inflight.cancelled = true
+			inflight.cancel()
+		}
+		<-conn.mutex
+	}
+
+	if requestUnit.hasID() {
+		responseUnit = &ResponseUnit{JsonRPC: "2.0", ID: requestUnit.ID, Result: json.RawMessage("null")}
+	}
+
+	return
+}
+
+//--------------------------------------------------------------------------------//
@@ -0,0 +1,45 @@
+package jsonrpc2
+
+import "context"
+
+//--------------------------------------------------------------------------------//
+// SERVER MIDDLEWARE
+//--------------------------------------------------------------------------------//
+
+type ServerDispatchFunc func(context.Context, *RequestUnit) *ResponseUnit
+
+type ServerMiddleware func(ServerDispatchFunc) ServerDispatchFunc
+
+type ServerBatchFunc func(context.Context, RequestSlice) ResponseSlice
+
+type ServerBatchMiddleware func(ServerBatchFunc) ServerBatchFunc
+
+func (server *Server) Use(middlewareArray ...ServerMiddleware) {
+	server.middlewareArray = append(server.middlewareArray, middlewareArray...)
+}
+
+func (server *Server) UseBatch(middlewareArray ...ServerBatchMiddleware) {
+	server.batchMiddlewareArray = append(server.batchMiddlewareArray, middlewareArray...)
+}
+
+func (server *Server) dispatchUnit(ctx context.Context, requestUnit *RequestUnit) *ResponseUnit {
+	var dispatchFunc ServerDispatchFunc = server.executeUnit
+
+	for middlewareIndex := len(server.middlewareArray) - 1; middlewareIndex >= 0; middlewareIndex-- {
+		dispatchFunc = server.middlewareArray[middlewareIndex](dispatchFunc)
+	}
+
+	return dispatchFunc(ctx, requestUnit)
+}
+
+func (server *Server) dispatchSlice(ctx context.Context, requestSlice RequestSlice) ResponseSlice {
+	var batchFunc ServerBatchFunc = server.executeSlice
+
+	for middlewareIndex := len(server.batchMiddlewareArray) - 1; middlewareIndex >= 0; middlewareIndex-- {
+		batchFunc = server.batchMiddlewareArray[middlewareIndex](batchFunc)
+	}
+
+	return batchFunc(ctx, requestSlice)
+}
+
+//--------------------------------------------------------------------------------//
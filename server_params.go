@@ -0,0 +1,411 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//--------------------------------------------------------------------------------//
+// SERVER PARAMS
+//--------------------------------------------------------------------------------//
+
+var reflectTypeUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+type ServerHandlerParam struct {
+	Name     string
+	Default  interface{}
+	Optional bool
+
+	derived bool
+}
+
+type serverHandlerParamField struct {
+	name       string
+	indexArray []int
+	tagged     bool
+}
+
+type serverHandlerParamEmbed struct {
+	embedType  reflect.Type
+	indexArray []int
+}
+
+func WithParams(nameArray ...string) ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		for paramIndex, name := range nameArray {
+			if paramIndex < len(handlerUnit.Params) {
+				handlerUnit.Params[paramIndex].Name = name
+				handlerUnit.Params[paramIndex].derived = false
+			} else {
+				handlerUnit.Params = append(handlerUnit.Params, ServerHandlerParam{Name: name})
+			}
+		}
+	}
+}
+
+func WithParamDefault(name string, value interface{}) ServerHandlerOption {
+	return func(handlerUnit *ServerHandlerUnit) {
+		for paramIndex := range handlerUnit.Params {
+			if handlerUnit.Params[paramIndex].Name == name {
+				handlerUnit.Params[paramIndex].Default = value
+				handlerUnit.Params[paramIndex].Optional = true
+				handlerUnit.Params[paramIndex].derived = false
+				return
+			}
+		}
+
+		handlerUnit.Params = append(handlerUnit.Params, ServerHandlerParam{
+			Name:     name,
+			Default:  value,
+			Optional: true,
+		})
+	}
+}
+
+func newServerHandlerParamArray(requestType reflect.Type) (paramArray []ServerHandlerParam) {
+	for _, fieldName := range paramFieldArray(requestType) {
+		paramArray = append(paramArray, ServerHandlerParam{Name: fieldName, derived: true})
+	}
+
+	return
+}
+
+// paramFieldArray lists the JSON names of a struct's fields in field order,
+// resolving embedded structs the way encoding/json does: the shallowest field
+// wins, a tagged one breaks a tie, and names that stay ambiguous are dropped.
+func paramFieldArray(requestType reflect.Type) (fieldArray []string) {
+	var (
+		currentArray   []serverHandlerParamEmbed
+		nextArray      []serverHandlerParamEmbed
+		visitedMap     = map[reflect.Type]bool{}
+		paramFieldList []serverHandlerParamField
+		paramFieldMap  = map[string][]serverHandlerParamField{}
+		structField    reflect.StructField
+		fieldName      string
+		fieldType      reflect.Type
+		indexArray     []int
+	)
+
+	for requestType.Kind() == reflect.Ptr {
+		requestType = requestType.Elem()
+	}
+
+	if requestType.Kind() != reflect.Struct || reflect.PtrTo(requestType).Implements(reflectTypeUnmarshaler) {
+		return nil
+	}
+
+	nextArray = append(nextArray, serverHandlerParamEmbed{embedType: requestType})
+
+	for len(nextArray) > 0 {
+		currentArray, nextArray = nextArray, nil
+
+		for _, embed := range currentArray {
+			if visitedMap[embed.embedType] {
+				continue
+			}
+
+			visitedMap[embed.embedType] = true
+
+			for fieldIndex := 0; fieldIndex < embed.embedType.NumField(); fieldIndex++ {
+				structField = embed.embedType.Field(fieldIndex)
+
+				fieldType = structField.Type
+				for fieldType.Kind() == reflect.Ptr {
+					fieldType = fieldType.Elem()
+				}
+
+				if structField.PkgPath != "" && (!structField.Anonymous || fieldType.Kind() != reflect.Struct) {
+					continue
+				}
+
+				fieldName = ""
+				if fieldTag, ok := structField.Tag.Lookup("json"); ok {
+					fieldName = strings.Split(fieldTag, ",")[0]
+					if fieldName == "-" {
+						continue
+					}
+				}
+
+				indexArray = append(append([]int{}, embed.indexArray...), fieldIndex)
+
+				if fieldName == "" && structField.Anonymous && fieldType.Kind() == reflect.Struct {
+					nextArray = append(nextArray, serverHandlerParamEmbed{embedType: fieldType, indexArray: indexArray})
+					continue
+				}
+
+				if structField.PkgPath != "" {
+					continue
+				}
+
+				paramField := serverHandlerParamField{name: fieldName, indexArray: indexArray, tagged: fieldName != ""}
+				if paramField.name == "" {
+					paramField.name = structField.Name
+				}
+
+				paramFieldList = append(paramFieldList, paramField)
+				paramFieldMap[paramField.name] = append(paramFieldMap[paramField.name], paramField)
+			}
+		}
+	}
+
+	sort.Slice(paramFieldList, func(leftIndex int, rightIndex int) bool {
+		leftArray, rightArray := paramFieldList[leftIndex].indexArray, paramFieldList[rightIndex].indexArray
+
+		for index := 0; index < len(leftArray) && index < len(rightArray); index++ {
+			if leftArray[index] != rightArray[index] {
+				return leftArray[index] < rightArray[index]
+			}
+		}
+
+		return len(leftArray) < len(rightArray)
+	})
+
+	fieldArray = []string{}
+
+	for _, paramField := range paramFieldList {
+		if paramFieldDominant(paramField, paramFieldMap[paramField.name]) {
+			fieldArray = append(fieldArray, paramField.name)
+		}
+	}
+
+	return
+}
+
+func paramFieldDominant(paramField serverHandlerParamField, candidateArray []serverHandlerParamField) bool {
+	for _, otherField := range candidateArray {
+		if reflect.DeepEqual(otherField.indexArray, paramField.indexArray) {
+			continue
+		}
+
+		switch {
+		case len(otherField.indexArray) < len(paramField.indexArray):
+			return false
+		case len(otherField.indexArray) > len(paramField.indexArray):
+		case otherField.tagged == paramField.tagged || otherField.tagged:
+			return false
+		}
+	}
+
+	return true
+}
+
+func (handler *ServerHandlerUnit) checkParams() (err error) {
+	var (
+		fieldArray []string
+		nameMap    = map[string]int{}
+	)
+
+	if len(handler.Params) == 0 {
+		return
+	}
+
+	if handler.Request == nil {
+		return fmt.Errorf("handler without params declares %d params", len(handler.Params))
+	}
+
+	fieldArray = paramFieldArray(handler.Request)
+	if fieldArray == nil {
+		fieldArray = []string{""}
+	}
+
+	for _, param := range handler.Params {
+		nameMap[param.Name]++
+	}
+
+	for paramIndex, param := range handler.Params {
+		if param.derived {
+			continue
+		}
+
+		if paramIndex >= len(fieldArray) {
+			return fmt.Errorf(`param "%s" does not match any of %d handler params`, param.Name, len(fieldArray))
+		}
+
+		if param.Name == "" {
+			return fmt.Errorf("param %d has no name", paramIndex)
+		}
+
+		if nameMap[param.Name] > 1 {
+			return fmt.Errorf(`param "%s" is declared twice`, param.Name)
+		}
+
+		if param.Optional {
+			_, err = json.Marshal(param.Default)
+			if err != nil {
+				return fmt.Errorf(`param "%s" default: %s`, param.Name, err.Error())
+			}
+		}
+	}
+
+	return
+}
+
+func (handler *ServerHandlerUnit) paramsRenamed(fieldArray []string) bool {
+	for paramIndex, param := range handler.Params {
+		if param.Optional || param.Name != fieldArray[paramIndex] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (handler *ServerHandlerUnit) bindParams(params json.RawMessage) (json.RawMessage, *Error) {
+	var (
+		paramsJson  = bytes.TrimSpace(params)
+		paramArray  []json.RawMessage
+		paramMap    map[string]json.RawMessage
+		fieldArray  []string
+		nameArray   []string
+		defaultJson []byte
+		err         error
+	)
+
+	if handler.Request == nil {
+		return params, nil
+	}
+
+	if len(paramsJson) == 0 {
+		optional := false
+		for _, param := range handler.Params {
+			optional = optional || param.Optional
+		}
+
+		if !optional {
+			return params, nil
+		}
+	}
+
+	fieldArray = paramFieldArray(handler.Request)
+
+	nameArray = make([]string, len(handler.Params))
+	for paramIndex, param := range handler.Params {
+		nameArray[paramIndex] = param.Name
+	}
+
+	if fieldArray == nil {
+		if len(paramsJson) == 0 {
+			for _, param := range handler.Params {
+				if param.Optional {
+					defaultJson, err = json.Marshal(param.Default)
+					if err != nil {
+						return nil, NewErrorInternalError(err.Error())
+					}
+
+					break
+				}
+			}
+
+			return defaultJson, nil
+		}
+
+		requestKind := handler.Request.Kind()
+		if requestKind == reflect.Ptr {
+			requestKind = handler.Request.Elem().Kind()
+		}
+
+		switch {
+		case paramsJson[0] == '[' && len(nameArray) == 0 && requestKind != reflect.Slice && requestKind != reflect.Array && requestKind != reflect.Interface:
+			if json.Unmarshal(paramsJson, &paramArray) == nil && len(paramArray) == 1 {
+				return paramArray[0], nil
+			}
+		case paramsJson[0] == '[' && len(nameArray) == 0 && (requestKind == reflect.Slice || requestKind == reflect.Array):
+			if json.Unmarshal(paramsJson, &paramArray) == nil && len(paramArray) == 1 && json.Unmarshal(paramsJson, reflect.New(handler.Request).Interface()) != nil {
+				return paramArray[0], nil
+			}
+		case paramsJson[0] == '[' && len(nameArray) == 1:
+			if json.Unmarshal(paramsJson, &paramArray) == nil && len(paramArray) == 1 {
+				return paramArray[0], nil
+			}
+		case paramsJson[0] == '{' && len(nameArray) == 1:
+			if json.Unmarshal(paramsJson, &paramMap) == nil && paramMap[nameArray[0]] != nil {
+				return paramMap[nameArray[0]], nil
+			}
+		}
+
+		return params, nil
+	}
+
+	if len(nameArray) == 0 {
+		nameArray = fieldArray
+	}
+
+	if len(nameArray) > len(fieldArray) {
+		return nil, NewErrorInternalError(fmt.Sprintf("handler declares %d params for %d fields", len(nameArray), len(fieldArray)))
+	}
+
+	if len(paramsJson) > 0 && paramsJson[0] == '{' && !handler.paramsRenamed(fieldArray) {
+		return params, nil
+	}
+
+	paramMap = map[string]json.RawMessage{}
+
+	switch {
+	case len(paramsJson) == 0:
+
+	case paramsJson[0] == '[':
+		err = json.Unmarshal(paramsJson, &paramArray)
+		if err != nil {
+			return nil, NewErrorInvalidParams(err.Error())
+		}
+
+		if len(paramArray) > len(nameArray) {
+			return nil, NewErrorInvalidParams(fmt.Sprintf("expected at most %d params, got %d", len(nameArray), len(paramArray)))
+		}
+
+		for paramIndex, paramJson := range paramArray {
+			paramMap[fieldArray[paramIndex]] = paramJson
+		}
+	case paramsJson[0] == '{':
+		var objectMap map[string]json.RawMessage
+
+		err = json.Unmarshal(paramsJson, &objectMap)
+		if err != nil {
+			return nil, NewErrorInvalidParams(err.Error())
+		}
+
+		for name, paramJson := range objectMap {
+			paramMap[name] = paramJson
+		}
+
+		for _, name := range nameArray {
+			delete(paramMap, name)
+		}
+
+		for paramIndex, name := range nameArray {
+			if paramJson, ok := objectMap[name]; ok {
+				paramMap[fieldArray[paramIndex]] = paramJson
+			}
+		}
+	default:
+		return params, nil
+	}
+
+	for paramIndex, param := range handler.Params {
+		if !param.Optional || paramIndex >= len(fieldArray) {
+			continue
+		}
+
+		if _, ok := paramMap[fieldArray[paramIndex]]; ok {
+			continue
+		}
+
+		paramMap[fieldArray[paramIndex]], err = json.Marshal(param.Default)
+		if err != nil {
+			return nil, NewErrorInternalError(err.Error())
+		}
+	}
+
+	paramsJson, err = json.Marshal(paramMap)
+	if err != nil {
+		return nil, NewErrorInternalError(err.Error())
+	}
+
+	return paramsJson, nil
+}
+
+//--------------------------------------------------------------------------------//
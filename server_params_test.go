@@ -0,0 +1,213 @@
+package jsonrpc2
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testParamsBase struct {
+	Scale int `json:"scale"`
+}
+
+type testParamsPoint struct {
+	testParamsBase
+
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type testParamsCoord struct {
+	X int
+	Y int
+}
+
+type testParamsLabel struct {
+	X string
+}
+
+type testParamsShadow struct {
+	testParamsCoord
+
+	X string `json:"X"`
+}
+
+type testParamsAmbiguous struct {
+	testParamsCoord
+	testParamsLabel
+}
+
+func testExecute(t *testing.T, server *Server, method string, params string) *ResponseUnit {
+	t.Helper()
+
+	requestUnit := &RequestUnit{JsonRPC: "2.0", ID: 1, Method: method}
+	if params != "" {
+		requestUnit.Params = json.RawMessage(params)
+	}
+
+	responseSlice := server.Execute(RequestSlice{requestUnit})
+	if len(responseSlice) != 1 {
+		t.Fatalf("%s %s: expected one response, got %d", method, params, len(responseSlice))
+	}
+
+	return responseSlice[0]
+}
+
+func TestBindParams(t *testing.T) {
+	server := NewServer()
+
+	mustRegister := func(method string, function interface{}, optionArray ...ServerHandlerOption) {
+		t.Helper()
+
+		if err := server.Register(method, function, optionArray...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mustRegister("add", func(a int, b int, c int) (int, error) {
+		return a*100 + b*10 + c, nil
+	}, WithParams("a", "b", "c"), WithParamDefault("c", 7))
+
+	mustRegister("point", func(point testParamsPoint) (int, error) {
+		return point.Scale*100 + point.X*10 + point.Y, nil
+	})
+
+	mustRegister("renamed", func(point testParamsPoint) (int, error) {
+		return point.Scale*100 + point.X*10 + point.Y, nil
+	}, WithParams("s", "left", "top"))
+
+	mustRegister("shadow", func(shadow testParamsShadow) (string, error) {
+		return shadow.X + strconv.Itoa(shadow.Y), nil
+	})
+
+	mustRegister("ambiguous", func(ambiguous testParamsAmbiguous) (int, error) {
+		return ambiguous.testParamsCoord.X*10 + ambiguous.Y, nil
+	})
+
+	mustRegister("square", func(value int) (int, error) {
+		return value * value, nil
+	}, WithParams("value"))
+
+	mustRegister("sum", func(valueArray []int) (int, error) {
+		sum := 0
+		for _, value := range valueArray {
+			sum += value
+		}
+
+		return sum, nil
+	})
+
+	mustRegister("since", func(moment time.Time) (int, error) {
+		return moment.Year(), nil
+	})
+
+	testArray := []struct {
+		method string
+		params string
+		result string
+		code   int32
+	}{
+		{"add", `[1, 2, 3]`, `123`, 0},
+		{"add", `[1, 2]`, `127`, 0},
+		{"add", `{"a": 1, "b": 2}`, `127`, 0},
+		{"add", `{"a": 1, "b": 2, "c": 3}`, `123`, 0},
+		{"add", `[1, 2, 3, 4]`, ``, -32602},
+
+		{"point", `[1, 2, 3]`, `123`, 0},
+		{"point", `{"scale": 1, "x": 2, "y": 3}`, `123`, 0},
+
+		{"renamed", `{"s": 1, "left": 2, "top": 3}`, `123`, 0},
+		{"renamed", `[1, 2, 3]`, `123`, 0},
+
+		{"shadow", `[2, "a"]`, `"a2"`, 0},
+		{"shadow", `{"Y": 2, "X": "a"}`, `"a2"`, 0},
+
+		{"ambiguous", `[2]`, `2`, 0},
+		{"ambiguous", `{"X": 1, "Y": 2}`, `2`, 0},
+		{"ambiguous", `[1, 2]`, ``, -32602},
+
+		{"square", `[3]`, `9`, 0},
+		{"square", `{"value": 3}`, `9`, 0},
+		{"square", `3`, `9`, 0},
+
+		{"sum", `[1, 2, 3]`, `6`, 0},
+		{"sum", `[[1, 2, 3]]`, `6`, 0},
+
+		{"since", `"2020-01-02T00:00:00Z"`, `2020`, 0},
+		{"since", `["2020-01-02T00:00:00Z"]`, `2020`, 0},
+	}
+
+	for _, test := range testArray {
+		responseUnit := testExecute(t, server, test.method, test.params)
+
+		if test.code != 0 {
+			if responseUnit.Error == nil || responseUnit.Error.Code != test.code {
+				t.Errorf("%s %s: expected error %d, got %+v", test.method, test.params, test.code, responseUnit)
+			}
+
+			continue
+		}
+
+		if responseUnit.Error != nil {
+			t.Errorf("%s %s: unexpected error %+v", test.method, test.params, responseUnit.Error)
+			continue
+		}
+
+		if string(responseUnit.Result) != test.result {
+			t.Errorf("%s %s: expected %s, got %s", test.method, test.params, test.result, responseUnit.Result)
+		}
+	}
+}
+
+func TestCheckParams(t *testing.T) {
+	server := NewServer()
+
+	add := func(a int, b int) (int, error) {
+		return a + b, nil
+	}
+
+	testArray := []struct {
+		name        string
+		optionArray []ServerHandlerOption
+		valid       bool
+	}{
+		{"positional", nil, true},
+		{"named", []ServerHandlerOption{WithParams("a", "b")}, true},
+		{"default", []ServerHandlerOption{WithParams("a", "b"), WithParamDefault("b", 1)}, true},
+		{"default typo", []ServerHandlerOption{WithParams("a", "b"), WithParamDefault("c", 1)}, false},
+		{"too many names", []ServerHandlerOption{WithParams("a", "b", "c")}, false},
+		{"duplicate names", []ServerHandlerOption{WithParams("a", "a")}, false},
+		{"empty name", []ServerHandlerOption{WithParams("a", "")}, false},
+		{"invalid default", []ServerHandlerOption{WithParams("a", "b"), WithParamDefault("b", func() {})}, false},
+	}
+
+	for _, test := range testArray {
+		err := server.Register(test.name, add, test.optionArray...)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.name, test.valid, err)
+		}
+	}
+
+	if err := server.Register("shadow", func(shadow testParamsShadow) (string, error) { return shadow.X, nil }, WithParams("y", "x")); err != nil {
+		t.Errorf("shadow: unexpected error %v", err)
+	}
+
+	if err := server.Register("shadow duplicate", func(shadow testParamsShadow) (string, error) { return shadow.X, nil }, WithParams("X")); err == nil {
+		t.Errorf("shadow duplicate: expected an error for a name that repeats a field")
+	}
+
+	if err := server.Register("single", func(value int) (int, error) { return value, nil }, WithParams("x", "y")); err == nil {
+		t.Errorf("single: expected an error for two names on one param")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("HandleFunc: expected a panic for names on a handler without params")
+			}
+		}()
+
+		server.HandleFunc("nothing", func(interface{}) (interface{}, error) { return true, nil }, nil, true, WithParams("x"))
+	}()
+}
@@ -0,0 +1,291 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+//--------------------------------------------------------------------------------//
+// SERVER REGISTER
+//--------------------------------------------------------------------------------//
+
+var (
+	reflectTypeContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	reflectTypeError   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+func newServerHandlerUnit(functionValue reflect.Value) (handlerUnit ServerHandlerUnit, err error) {
+	var (
+		functionType  reflect.Type
+		withContext   bool
+		requestType   reflect.Type
+		responseType  reflect.Type
+		inputIndex    int
+		argumentArray []reflect.Type
+		multiInput    bool
+	)
+
+	if !functionValue.IsValid() || functionValue.Kind() != reflect.Func {
+		err = fmt.Errorf("handler must be a function, got %s", functionValue.Kind())
+		return
+	}
+
+	if functionValue.IsNil() {
+		err = fmt.Errorf("handler function is nil")
+		return
+	}
+
+	functionType = functionValue.Type()
+
+	if functionType.IsVariadic() {
+		err = fmt.Errorf("handler function %s must not be variadic", functionType)
+		return
+	}
+
+	if functionType.NumIn() > 0 && functionType.In(0) == reflectTypeContext {
+		withContext = true
+		inputIndex = 1
+	}
+
+	for argumentIndex := inputIndex; argumentIndex < functionType.NumIn(); argumentIndex++ {
+		if functionType.In(argumentIndex) == reflectTypeContext {
+			err = fmt.Errorf("handler function %s must accept context.Context only as the first argument", functionType)
+			return
+		}
+
+		argumentArray = append(argumentArray, functionType.In(argumentIndex))
+	}
+
+	switch len(argumentArray) {
+	case 0:
+
+	case 1:
+		requestType = argumentArray[0]
+	default:
+		requestType = newServerArgumentType(argumentArray)
+		multiInput = true
+	}
+
+	switch functionType.NumOut() {
+	case 1:
+
+	case 2:
+		responseType = functionType.Out(0)
+	default:
+		err = fmt.Errorf("handler function %s must return (result, error) or error", functionType)
+		return
+	}
+
+	if functionType.Out(functionType.NumOut()-1) != reflectTypeError {
+		err = fmt.Errorf("handler function %s must return error as the last result, got %s", functionType, functionType.Out(functionType.NumOut()-1))
+		return
+	}
+
+	handlerUnit = ServerHandlerUnit{
+		Request:  requestType,
+		Response: responseType,
+		ContextFunction: func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			var (
+				inputArray  = make([]reflect.Value, 0, len(argumentArray)+1)
+				outputArray []reflect.Value
+			)
+
+			if withContext {
+				if ctx == nil {
+					ctx = context.Background()
+				}
+
+				inputArray = append(inputArray, reflect.ValueOf(ctx))
+			}
+
+			if multiInput {
+				requestValue := reflect.New(requestType).Elem()
+				if request != nil {
+					requestValue = reflect.ValueOf(request)
+				}
+
+				for argumentIndex := range argumentArray {
+					inputArray = append(inputArray, requestValue.Field(argumentIndex))
+				}
+			} else if requestType != nil {
+				switch {
+				case request != nil:
+					inputArray = append(inputArray, reflect.ValueOf(request))
+				case requestType.Kind() == reflect.Ptr:
+					inputArray = append(inputArray, reflect.New(requestType.Elem()))
+				default:
+					inputArray = append(inputArray, reflect.Zero(requestType))
+				}
+			}
+
+			outputArray = functionValue.Call(inputArray)
+
+			if errInterface := outputArray[len(outputArray)-1].Interface(); errInterface != nil {
+				err = errInterface.(error)
+				return
+			}
+
+			if len(outputArray) > 1 {
+				response = outputArray[0].Interface()
+			}
+
+			if response == nil {
+				response = json.RawMessage("null")
+			}
+
+			return
+		},
+	}
+
+	if responseType == nil {
+		handlerUnit.ResultSchema = &Schema{Type: "null"}
+	}
+
+	return
+}
+
+func newServerArgumentType(argumentArray []reflect.Type) reflect.Type {
+	fieldArray := make([]reflect.StructField, len(argumentArray))
+
+	for argumentIndex, argumentType := range argumentArray {
+		fieldArray[argumentIndex] = reflect.StructField{
+			Name: fmt.Sprintf("Arg%d", argumentIndex),
+			Type: argumentType,
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"arg%d"`, argumentIndex)),
+		}
+	}
+
+	return reflect.StructOf(fieldArray)
+}
+
+func (server *Server) Register(method string, function interface{}, optionArray ...ServerHandlerOption) (err error) {
+	var handlerUnit ServerHandlerUnit
+
+	if method == "" {
+		return fmt.Errorf("register: method name is empty")
+	}
+
+	handlerUnit, err = newServerHandlerUnit(reflect.ValueOf(function))
+	if err != nil {
+		return fmt.Errorf(`register "%s": %s`, method, err.Error())
+	}
+
+	err = server.handle(method, handlerUnit, optionArray)
+	if err != nil {
+		return fmt.Errorf(`register "%s": %s`, method, err.Error())
+	}
+
+	return
+}
+
+//--------------------------------------------------------------------------------//
+// SERVER SERVICE
+//--------------------------------------------------------------------------------//
+
+type serverServiceConfig struct {
+	separator  string
+	lowercase  bool
+	excludeMap map[string]bool
+}
+
+type ServerServiceOption func(*serverServiceConfig)
+
+func WithServiceSeparator(separator string) ServerServiceOption {
+	return func(config *serverServiceConfig) {
+		config.separator = separator
+	}
+}
+
+func WithServiceLowercase() ServerServiceOption {
+	return func(config *serverServiceConfig) {
+		config.lowercase = true
+	}
+}
+
+func WithServiceExclude(methodArray ...string) ServerServiceOption {
+	return func(config *serverServiceConfig) {
+		for _, method := range methodArray {
+			config.excludeMap[method] = true
+		}
+	}
+}
+
+func (server *Server) RegisterService(name string, receiver interface{}, optionArray ...ServerServiceOption) (err error) {
+	var (
+		receiverValue reflect.Value
+		receiverType  reflect.Type
+		reflectMethod reflect.Method
+		handlerMap    = map[string]ServerHandlerUnit{}
+		handlerUnit   ServerHandlerUnit
+		method        string
+		config        = &serverServiceConfig{
+			separator:  ".",
+			excludeMap: map[string]bool{},
+		}
+	)
+
+	for _, option := range optionArray {
+		option(config)
+	}
+
+	receiverValue = reflect.ValueOf(receiver)
+	if !receiverValue.IsValid() {
+		return fmt.Errorf("register service: receiver is nil")
+	}
+
+	receiverType = receiverValue.Type()
+
+	if name == "" {
+		name = reflect.Indirect(receiverValue).Type().Name()
+		if name == "" {
+			return fmt.Errorf("register service: no service name for type %s", receiverType)
+		}
+	}
+
+	for methodIndex := 0; methodIndex < receiverType.NumMethod(); methodIndex++ {
+		reflectMethod = receiverType.Method(methodIndex)
+
+		if reflectMethod.PkgPath != "" || config.excludeMap[reflectMethod.Name] {
+			continue
+		}
+
+		handlerUnit, err = newServerHandlerUnit(receiverValue.Method(methodIndex))
+		if err != nil {
+			err = nil
+			continue
+		}
+
+		method = reflectMethod.Name
+		if config.lowercase {
+			methodRune, methodRuneSize := utf8.DecodeRuneInString(method)
+			method = string(unicode.ToLower(methodRune)) + method[methodRuneSize:]
+		}
+
+		handlerMap[name+config.separator+method] = handlerUnit
+	}
+
+	if len(handlerMap) == 0 {
+		return fmt.Errorf(`register service "%s": type %s has no suitable exported methods`, name, receiverType)
+	}
+
+	for method, handlerUnit = range handlerMap {
+		err = handlerUnit.prepare(nil)
+		if err != nil {
+			return fmt.Errorf(`register service "%s": %s: %s`, name, method, err.Error())
+		}
+
+		handlerMap[method] = handlerUnit
+	}
+
+	for method, handlerUnit = range handlerMap {
+		server.handlerMap[method] = handlerUnit
+	}
+
+	return
+}
+
+//--------------------------------------------------------------------------------//
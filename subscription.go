@@ -0,0 +1,562 @@
+package jsonrpc2
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//--------------------------------------------------------------------------------//
+// SUBSCRIPTION
+//--------------------------------------------------------------------------------//
+
+const (
+	subscriptionSubscribeSuffix    = "_subscribe"
+	subscriptionUnsubscribeSuffix  = "_unsubscribe"
+	subscriptionNotificationSuffix = "_subscription"
+
+	subscriptionQueueSize = 1024
+)
+
+type subscriptionParams struct {
+	Subscription string          `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+type subscriptionActivation struct {
+	mutex            chan interface{}
+	activated        bool
+	subscriptionList []*Subscription
+}
+
+func (activation *subscriptionActivation) add(subscription *Subscription) {
+	activation.mutex <- true
+	if activation.activated {
+		subscription.activate()
+	} else {
+		activation.subscriptionList = append(activation.subscriptionList, subscription)
+	}
+	<-activation.mutex
+}
+
+func (activation *subscriptionActivation) activate() {
+	activation.mutex <- true
+	activation.activated = true
+	for _, subscription := range activation.subscriptionList {
+		subscription.activate()
+	}
+	activation.subscriptionList = nil
+	<-activation.mutex
+}
+
+type subscriptionActivationKey struct{}
+
+func newSubscriptionID() (string, error) {
+	var idBuffer [16]byte
+
+	_, err := rand.Read(idBuffer[:])
+	if err != nil {
+		return "", err
+	}
+
+	return "0x" + hex.EncodeToString(idBuffer[:]), nil
+}
+
+//--------------------------------------------------------------------------------//
+// SERVER SUBSCRIPTION
+//--------------------------------------------------------------------------------//
+
+type Subscription struct {
+	ID string
+
+	namespace string
+	conn      *Conn
+
+	context context.Context
+	cancel  context.CancelFunc
+
+	notifyMutex  chan interface{}
+	activated    bool
+	pendingArray []json.RawMessage
+}
+
+func (subscription *Subscription) activate() {
+	subscription.notifyMutex <- true
+	defer func() {
+		<-subscription.notifyMutex
+	}()
+
+	if subscription.activated {
+		return
+	}
+
+	subscription.activated = true
+
+	for _, resultJson := range subscription.pendingArray {
+		if subscription.context.Err() != nil || subscription.send(resultJson) != nil {
+			break
+		}
+	}
+
+	subscription.pendingArray = nil
+}
+
+func (subscription *Subscription) send(resultJson json.RawMessage) error {
+	return subscription.conn.Notify(subscription.namespace+subscriptionNotificationSuffix, &subscriptionParams{
+		Subscription: subscription.ID,
+		Result:       resultJson,
+	})
+}
+
+func (subscription *Subscription) Namespace() string {
+	return subscription.namespace
+}
+
+func (subscription *Subscription) Context() context.Context {
+	return subscription.context
+}
+
+func (subscription *Subscription) Done() <-chan struct{} {
+	return subscription.context.Done()
+}
+
+func (subscription *Subscription) Notify(result interface{}) (err error) {
+	var resultJson json.RawMessage
+
+	if subscription.context.Err() != nil {
+		return fmt.Errorf("subscription %s is closed", subscription.ID)
+	}
+
+	resultJson, err = json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	subscription.notifyMutex <- true
+	defer func() {
+		<-subscription.notifyMutex
+	}()
+
+	if !subscription.activated {
+		if len(subscription.pendingArray) >= subscriptionQueueSize {
+			return fmt.Errorf("subscription %s is not active yet and its queue is full", subscription.ID)
+		}
+
+		subscription.pendingArray = append(subscription.pendingArray, resultJson)
+		return
+	}
+
+	return subscription.send(resultJson)
+}
+
+func (subscription *Subscription) Unsubscribe() {
+	subscription.conn.mutex <- true
+	delete(subscription.conn.subscriptionMap, subscription.ID)
+	<-subscription.conn.mutex
+
+	subscription.cancel()
+}
+
+func NewSubscription(ctx context.Context, namespace string) (subscription *Subscription, err error) {
+	var (
+		conn       = ConnFromContext(ctx)
+		activation *subscriptionActivation
+	)
+
+	if conn == nil {
+		return nil, NewErrorServerError(0, "subscriptions require a duplex connection")
+	}
+
+	subscription = &Subscription{
+		namespace: namespace,
+		conn:      conn,
+
+		notifyMutex: make(chan interface{}, 1),
+	}
+
+	subscription.ID, err = newSubscriptionID()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.context, subscription.cancel = context.WithCancel(conn.context)
+
+	conn.mutex <- true
+
+	if conn.closeError != nil {
+		err = conn.closeError
+	} else {
+		conn.subscriptionMap[subscription.ID] = subscription
+	}
+
+	<-conn.mutex
+
+	if err != nil {
+		subscription.cancel()
+		return nil, err
+	}
+
+	activation, _ = ctx.Value(subscriptionActivationKey{}).(*subscriptionActivation)
+	if activation != nil {
+		activation.add(subscription)
+	} else {
+		subscription.activate()
+	}
+
+	return
+}
+
+type ServerSubscribeFunc func(ctx context.Context, subscription *Subscription, params json.RawMessage) error
+
+func (server *Server) HandleSubscription(namespace string, event string, subscribeFunc ServerSubscribeFunc) {
+	eventMap := server.subscriptionMap[namespace]
+
+	if eventMap == nil {
+		eventMap = map[string]ServerSubscribeFunc{}
+		server.subscriptionMap[namespace] = eventMap
+
+		server.HandleContextFunc(namespace+subscriptionSubscribeSuffix, func(ctx context.Context, request interface{}) (interface{}, error) {
+			var (
+				paramArray, _ = request.([]json.RawMessage)
+				paramEvent    string
+				paramJson     json.RawMessage
+				subscription  *Subscription
+				eventFunc     ServerSubscribeFunc
+				err           error
+			)
+
+			if len(paramArray) == 0 || len(paramArray) > 2 || json.Unmarshal(paramArray[0], &paramEvent) != nil {
+				return nil, NewErrorInvalidParams("expected [event, params]")
+			}
+
+			if len(paramArray) == 2 {
+				paramJson = paramArray[1]
+			}
+
+			eventFunc = eventMap[paramEvent]
+			if eventFunc == nil {
+				return nil, NewErrorMethodNotFound(fmt.Sprintf(`subscription "%s" not founded`, paramEvent))
+			}
+
+			subscription, err = NewSubscription(ctx, namespace)
+			if err != nil {
+				return nil, err
+			}
+
+			err = eventFunc(ctx, subscription, paramJson)
+			if err != nil {
+				subscription.Unsubscribe()
+				return nil, err
+			}
+
+			return subscription.ID, nil
+		}, []json.RawMessage{}, "")
+
+		server.HandleContextFunc(namespace+subscriptionUnsubscribeSuffix, func(ctx context.Context, request interface{}) (interface{}, error) {
+			var (
+				conn          = ConnFromContext(ctx)
+				paramArray, _ = request.([]string)
+				subscription  *Subscription
+			)
+
+			if conn == nil {
+				return nil, NewErrorServerError(0, "subscriptions require a duplex connection")
+			}
+
+			if len(paramArray) != 1 {
+				return nil, NewErrorInvalidParams("expected [subscription]")
+			}
+
+			conn.mutex <- true
+			subscription = conn.subscriptionMap[paramArray[0]]
+			<-conn.mutex
+
+			if subscription == nil || subscription.namespace != namespace {
+				return false, nil
+			}
+
+			subscription.Unsubscribe()
+
+			return true, nil
+		}, []string{}, true)
+	}
+
+	eventMap[event] = subscribeFunc
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT SUBSCRIPTION
+//--------------------------------------------------------------------------------//
+
+type ClientSubscription struct {
+	ID string
+
+	namespace string
+	conn      *Conn
+
+	channel reflect.Value
+
+	queueMutex  chan interface{}
+	queueSignal chan interface{}
+	queue       []json.RawMessage
+
+	errChan   chan error
+	quitMutex chan interface{}
+	quitOnce  sync.Once
+}
+
+func (subscription *ClientSubscription) key() string {
+	return subscription.namespace + subscriptionNotificationSuffix + " " + subscription.ID
+}
+
+func (subscription *ClientSubscription) push(result json.RawMessage) bool {
+	subscription.queueMutex <- true
+
+	if len(subscription.queue) >= subscriptionQueueSize {
+		<-subscription.queueMutex
+		return false
+	}
+
+	subscription.queue = append(subscription.queue, result)
+	<-subscription.queueMutex
+
+	select {
+	case subscription.queueSignal <- true:
+	default:
+	}
+
+	return true
+}
+
+func (subscription *ClientSubscription) overflow() {
+	subscription.quit(fmt.Errorf("subscription %s: notification queue overflow", subscription.ID))
+
+	go subscription.conn.client.Request(subscription.namespace+subscriptionUnsubscribeSuffix, []string{subscription.ID}).Wait()
+}
+
+func (subscription *ClientSubscription) forward() {
+	var (
+		queue      []json.RawMessage
+		result     json.RawMessage
+		resultCase = []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(subscription.quitMutex)},
+			{Dir: reflect.SelectSend, Chan: subscription.channel},
+		}
+		resultType = subscription.channel.Type().Elem()
+	)
+
+	for {
+		select {
+		case <-subscription.queueSignal:
+		case <-subscription.quitMutex:
+			return
+		}
+
+		subscription.queueMutex <- true
+		queue, subscription.queue = subscription.queue, nil
+		<-subscription.queueMutex
+
+		for _, result = range queue {
+			resultValue := reflect.New(resultType)
+			if json.Unmarshal(result, resultValue.Interface()) != nil {
+				continue
+			}
+
+			resultCase[1].Send = resultValue.Elem()
+
+			if selectIndex, _, _ := reflect.Select(resultCase); selectIndex == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (subscription *ClientSubscription) quit(err error) {
+	subscription.quitOnce.Do(func() {
+		subscription.errChan <- err
+		close(subscription.errChan)
+		close(subscription.quitMutex)
+	})
+}
+
+func (subscription *ClientSubscription) Err() <-chan error {
+	return subscription.errChan
+}
+
+func (subscription *ClientSubscription) Unsubscribe() (err error) {
+	var executeError *Error
+
+	subscription.conn.mutex <- true
+	delete(subscription.conn.clientSubscriptionMap, subscription.key())
+	<-subscription.conn.mutex
+
+	subscription.quit(nil)
+
+	executeError = subscription.conn.client.Request(subscription.namespace+subscriptionUnsubscribeSuffix, []string{subscription.ID}).Response(nil)
+	if executeError != nil {
+		err = executeError
+	}
+
+	return
+}
+
+func (conn *Conn) Subscribe(ctx context.Context, namespace string, channel interface{}, event string, params interface{}) (subscription *ClientSubscription, err error) {
+	var (
+		channelValue  = reflect.ValueOf(channel)
+		paramArray    = []interface{}{event}
+		executeError  *Error
+		orphanArray   []json.RawMessage
+		subscribeID   string
+		subscribeWait = namespace + subscriptionNotificationSuffix
+	)
+
+	if channelValue.Kind() != reflect.Chan || channelValue.Type().ChanDir()&reflect.SendDir == 0 {
+		return nil, fmt.Errorf("subscribe: channel must be a writable channel, got %T", channel)
+	}
+
+	if params != nil {
+		paramArray = append(paramArray, params)
+	}
+
+	conn.mutex <- true
+	conn.subscribeWaitMap[subscribeWait]++
+	<-conn.mutex
+
+	defer func() {
+		conn.mutex <- true
+		conn.subscribeWaitMap[subscribeWait]--
+		if conn.subscribeWaitMap[subscribeWait] <= 0 {
+			delete(conn.subscribeWaitMap, subscribeWait)
+			for orphanKey := range conn.subscribeOrphanMap {
+				if strings.HasPrefix(orphanKey, subscribeWait+" ") {
+					delete(conn.subscribeOrphanMap, orphanKey)
+				}
+			}
+		}
+		<-conn.mutex
+	}()
+
+	executeError = conn.client.RequestContext(ctx, namespace+subscriptionSubscribeSuffix, paramArray).Response(&subscribeID)
+	if executeError != nil {
+		return nil, executeError
+	}
+
+	subscription = &ClientSubscription{
+		ID: subscribeID,
+
+		namespace: namespace,
+		conn:      conn,
+
+		channel: channelValue,
+
+		queueMutex:  make(chan interface{}, 1),
+		queueSignal: make(chan interface{}, 1),
+
+		errChan:   make(chan error, 1),
+		quitMutex: make(chan interface{}),
+	}
+
+	conn.mutex <- true
+
+	if conn.closeError != nil {
+		err = conn.closeError
+	} else {
+		conn.clientSubscriptionMap[subscription.key()] = subscription
+		orphanArray = conn.subscribeOrphanMap[subscription.key()]
+		delete(conn.subscribeOrphanMap, subscription.key())
+	}
+
+	<-conn.mutex
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, orphan := range orphanArray {
+		subscription.push(orphan)
+	}
+
+	go subscription.forward()
+
+	return
+}
+
+func (conn *Conn) receiveSubscription(method string, params json.RawMessage) bool {
+	var (
+		notificationParams subscriptionParams
+		notificationKey    string
+		subscription       *ClientSubscription
+	)
+
+	if !strings.HasSuffix(method, subscriptionNotificationSuffix) {
+		return false
+	}
+
+	if json.Unmarshal(params, &notificationParams) != nil || notificationParams.Subscription == "" {
+		return false
+	}
+
+	notificationKey = method + " " + notificationParams.Subscription
+
+	conn.mutex <- true
+	defer func() {
+		<-conn.mutex
+	}()
+
+	subscription = conn.clientSubscriptionMap[notificationKey]
+	if subscription != nil {
+		if !subscription.push(notificationParams.Result) {
+			delete(conn.clientSubscriptionMap, notificationKey)
+			subscription.overflow()
+		}
+
+		return true
+	}
+
+	if conn.subscribeWaitMap[method] > 0 {
+		if len(conn.subscribeOrphanMap[notificationKey]) < subscriptionQueueSize {
+			conn.subscribeOrphanMap[notificationKey] = append(conn.subscribeOrphanMap[notificationKey], notificationParams.Result)
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (conn *Conn) closeSubscription(err error) {
+	var (
+		subscriptionArray       []*Subscription
+		clientSubscriptionArray []*ClientSubscription
+	)
+
+	conn.mutex <- true
+
+	for _, subscription := range conn.subscriptionMap {
+		subscriptionArray = append(subscriptionArray, subscription)
+	}
+
+	for _, clientSubscription := range conn.clientSubscriptionMap {
+		clientSubscriptionArray = append(clientSubscriptionArray, clientSubscription)
+	}
+
+	conn.subscriptionMap = map[string]*Subscription{}
+	conn.clientSubscriptionMap = map[string]*ClientSubscription{}
+
+	<-conn.mutex
+
+	for _, subscription := range subscriptionArray {
+		subscription.cancel()
+	}
+
+	for _, clientSubscription := range clientSubscriptionArray {
+		clientSubscription.quit(err)
+	}
+}
+
+//--------------------------------------------------------------------------------//
@@ -0,0 +1,58 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net"
+)
+
+//--------------------------------------------------------------------------------//
+// SERVER CONN
+//--------------------------------------------------------------------------------//
+
+func (server *Server) ServeCodec(ctx context.Context, codec Codec) error {
+	return NewConnContext(ctx, codec, server).Wait()
+}
+
+func (server *Server) ServeConn(netConn net.Conn) error {
+	return server.ServeCodec(context.Background(), NewCodecStream(netConn))
+}
+
+func (server *Server) Serve(listener net.Listener) (err error) {
+	var netConn net.Conn
+
+	for {
+		netConn, err = listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go server.ServeConn(netConn)
+	}
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT TRANSPORT CODEC
+//--------------------------------------------------------------------------------//
+
+func NewClientTransportCodec(codec Codec) *Conn {
+	return NewConn(codec, nil)
+}
+
+func NewClientTransportConn(netConn net.Conn) *Conn {
+	return NewClientTransportCodec(NewCodecStream(netConn))
+}
+
+func DialClientTransportConn(network string, address string) (clientTransport *Conn, err error) {
+	var netConn net.Conn
+
+	netConn, err = net.Dial(network, address)
+	if err != nil {
+		return
+	}
+
+	clientTransport = NewClientTransportConn(netConn)
+
+	return
+}
+
+//--------------------------------------------------------------------------------//
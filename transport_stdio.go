@@ -0,0 +1,152 @@
+package jsonrpc2
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+//--------------------------------------------------------------------------------//
+// STDIO
+//--------------------------------------------------------------------------------//
+
+type stdioStream struct {
+	io.Reader
+	io.Writer
+
+	closerArray []io.Closer
+}
+
+func (stream *stdioStream) Close() (err error) {
+	for _, closer := range stream.closerArray {
+		if closeError := closer.Close(); closeError != nil && err == nil {
+			err = closeError
+		}
+	}
+
+	return
+}
+
+func newStdioCodec(codecFunc CodecFunc, reader io.Reader, writer io.Writer, closerArray ...io.Closer) Codec {
+	if codecFunc == nil {
+		codecFunc = NewCodecFuncStream()
+	}
+
+	return codecFunc(&stdioStream{
+		Reader: reader,
+		Writer: writer,
+
+		closerArray: closerArray,
+	})
+}
+
+//--------------------------------------------------------------------------------//
+// SERVER STDIO
+//--------------------------------------------------------------------------------//
+
+func (server *Server) ServeStdio(codecFunc CodecFunc) error {
+	return server.ServeCodec(context.Background(), newStdioCodec(codecFunc, os.Stdin, os.Stdout, os.Stdin, os.Stdout))
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT TRANSPORT STDIO
+//--------------------------------------------------------------------------------//
+
+func NewClientTransportStdio(codecFunc CodecFunc) *Conn {
+	return NewClientTransportCodec(newStdioCodec(codecFunc, os.Stdin, os.Stdout, os.Stdin, os.Stdout))
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT TRANSPORT COMMAND
+//--------------------------------------------------------------------------------//
+
+type ClientTransportCommand struct {
+	*Conn
+
+	command      *exec.Cmd
+	closeTimeout time.Duration
+
+	exitMutex chan interface{}
+	exitError error
+}
+
+func (clientTransport *ClientTransportCommand) wait() {
+	<-clientTransport.Conn.closeMutex
+
+	clientTransport.exitError = clientTransport.command.Wait()
+	close(clientTransport.exitMutex)
+}
+
+func (clientTransport *ClientTransportCommand) SetCloseTimeout(closeTimeout time.Duration) {
+	clientTransport.closeTimeout = closeTimeout
+}
+
+func (clientTransport *ClientTransportCommand) Done() <-chan interface{} {
+	return clientTransport.exitMutex
+}
+
+func (clientTransport *ClientTransportCommand) Err() error {
+	select {
+	case <-clientTransport.exitMutex:
+		return clientTransport.exitError
+	default:
+		return nil
+	}
+}
+
+func (clientTransport *ClientTransportCommand) Close() (err error) {
+	err = clientTransport.Conn.Close()
+
+	select {
+	case <-clientTransport.exitMutex:
+	case <-time.After(clientTransport.closeTimeout):
+		clientTransport.command.Process.Kill()
+		<-clientTransport.exitMutex
+	}
+
+	return
+}
+
+func NewClientTransportCommand(command *exec.Cmd, codecFunc CodecFunc, stderr io.Writer) (clientTransport *ClientTransportCommand, err error) {
+	var (
+		commandStdin  io.WriteCloser
+		commandStdout io.ReadCloser
+	)
+
+	commandStdin, err = command.StdinPipe()
+	if err != nil {
+		return
+	}
+
+	commandStdout, err = command.StdoutPipe()
+	if err != nil {
+		commandStdin.Close()
+		return
+	}
+
+	command.Stderr = stderr
+
+	err = command.Start()
+	if err != nil {
+		commandStdin.Close()
+		commandStdout.Close()
+		return
+	}
+
+	clientTransport = &ClientTransportCommand{
+		Conn: NewClientTransportCodec(newStdioCodec(codecFunc, commandStdout, commandStdin, commandStdin)),
+
+		command:      command,
+		closeTimeout: 5 * time.Second,
+
+		exitMutex: make(chan interface{}),
+	}
+
+	go clientTransport.wait()
+
+	return
+}
+
+//--------------------------------------------------------------------------------//
@@ -0,0 +1,691 @@
+package jsonrpc2
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//--------------------------------------------------------------------------------//
+// WEBSOCKET ERROR
+//--------------------------------------------------------------------------------//
+
+const (
+	WebSocketCloseNormal          = 1000
+	WebSocketCloseGoingAway       = 1001
+	WebSocketCloseProtocolError   = 1002
+	WebSocketCloseUnsupportedData = 1003
+	WebSocketCloseNoStatus        = 1005
+	WebSocketCloseAbnormal        = 1006
+	WebSocketCloseInvalidPayload  = 1007
+	WebSocketClosePolicyViolation = 1008
+	WebSocketCloseMessageTooBig   = 1009
+	WebSocketCloseInternalError   = 1011
+)
+
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (err *WebSocketCloseError) Error() string {
+	if err.Reason == "" {
+		return fmt.Sprintf("websocket closed with code %d", err.Code)
+	}
+
+	return fmt.Sprintf("websocket closed with code %d: %s", err.Code, err.Reason)
+}
+
+func (err *WebSocketCloseError) ErrorRPC() *Error {
+	return NewErrorInternalError(map[string]interface{}{
+		"websocketCode":   err.Code,
+		"websocketReason": err.Reason,
+	})
+}
+
+//--------------------------------------------------------------------------------//
+// WEBSOCKET OPTION
+//--------------------------------------------------------------------------------//
+
+type webSocketConfig struct {
+	maxMessageSize int64
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	checkOrigin    func(*http.Request) bool
+	header         http.Header
+	tlsConfig      *tls.Config
+}
+
+type WebSocketOption func(*webSocketConfig)
+
+func WithWebSocketMaxMessageSize(maxMessageSize int64) WebSocketOption {
+	return func(config *webSocketConfig) {
+		config.maxMessageSize = maxMessageSize
+	}
+}
+
+func WithWebSocketKeepAlive(pingInterval time.Duration, pongTimeout time.Duration) WebSocketOption {
+	return func(config *webSocketConfig) {
+		config.pingInterval = pingInterval
+		config.pongTimeout = pongTimeout
+	}
+}
+
+func WithWebSocketCheckOrigin(checkOrigin func(*http.Request) bool) WebSocketOption {
+	return func(config *webSocketConfig) {
+		config.checkOrigin = checkOrigin
+	}
+}
+
+func WithWebSocketHeader(header http.Header) WebSocketOption {
+	return func(config *webSocketConfig) {
+		config.header = header
+	}
+}
+
+func WithWebSocketTLSConfig(tlsConfig *tls.Config) WebSocketOption {
+	return func(config *webSocketConfig) {
+		config.tlsConfig = tlsConfig
+	}
+}
+
+func newWebSocketConfig(optionArray []WebSocketOption) *webSocketConfig {
+	config := &webSocketConfig{
+		maxMessageSize: 1 << 20,
+		checkOrigin:    webSocketCheckSameOrigin,
+	}
+
+	for _, option := range optionArray {
+		option(config)
+	}
+
+	return config
+}
+
+func webSocketCheckSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	originUrl, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(originUrl.Host, r.Host)
+}
+
+//--------------------------------------------------------------------------------//
+// WEBSOCKET CODEC
+//--------------------------------------------------------------------------------//
+
+const (
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	webSocketOpContinuation = 0x0
+	webSocketOpText         = 0x1
+	webSocketOpBinary       = 0x2
+	webSocketOpClose        = 0x8
+	webSocketOpPing         = 0x9
+	webSocketOpPong         = 0xa
+)
+
+type CodecWebSocket struct {
+	Codec
+
+	conn   net.Conn
+	reader *bufio.Reader
+	client bool
+	config *webSocketConfig
+
+	readError error
+
+	writeMutex chan interface{}
+	closeSent  bool
+
+	closeMutex chan interface{}
+}
+
+func (codec *CodecWebSocket) readDeadline() {
+	if codec.config.pingInterval > 0 {
+		codec.conn.SetReadDeadline(time.Now().Add(codec.config.pingInterval + codec.config.pongTimeout))
+	}
+}
+
+func (codec *CodecWebSocket) readFrame() (frameFinal bool, frameOpcode byte, framePayload []byte, err error) {
+	var (
+		frameHeader  [2]byte
+		frameLength  uint64
+		frameMasked  bool
+		frameMaskKey [4]byte
+		frameExtend  [8]byte
+	)
+
+	_, err = io.ReadFull(codec.reader, frameHeader[:])
+	if err != nil {
+		return
+	}
+
+	frameFinal = frameHeader[0]&0x80 != 0
+	frameOpcode = frameHeader[0] & 0x0f
+	frameMasked = frameHeader[1]&0x80 != 0
+	frameLength = uint64(frameHeader[1] & 0x7f)
+
+	if frameHeader[0]&0x70 != 0 {
+		err = &WebSocketCloseError{Code: WebSocketCloseProtocolError, Reason: "reserved bits are set"}
+		return
+	}
+
+	if frameMasked == codec.client {
+		err = &WebSocketCloseError{Code: WebSocketCloseProtocolError, Reason: "invalid frame masking"}
+		return
+	}
+
+	switch frameLength {
+	case 126:
+		_, err = io.ReadFull(codec.reader, frameExtend[:2])
+		frameLength = uint64(binary.BigEndian.Uint16(frameExtend[:2]))
+	case 127:
+		_, err = io.ReadFull(codec.reader, frameExtend[:8])
+		frameLength = binary.BigEndian.Uint64(frameExtend[:8])
+	}
+
+	if err != nil {
+		return
+	}
+
+	if frameOpcode >= webSocketOpClose && (!frameFinal || frameLength > 125) {
+		err = &WebSocketCloseError{Code: WebSocketCloseProtocolError, Reason: "invalid control frame"}
+		return
+	}
+
+	if codec.config.maxMessageSize > 0 && frameLength > uint64(codec.config.maxMessageSize) {
+		err = &WebSocketCloseError{Code: WebSocketCloseMessageTooBig, Reason: "message too big"}
+		return
+	}
+
+	if frameMasked {
+		_, err = io.ReadFull(codec.reader, frameMaskKey[:])
+		if err != nil {
+			return
+		}
+	}
+
+	framePayload = make([]byte, frameLength)
+
+	_, err = io.ReadFull(codec.reader, framePayload)
+	if err != nil {
+		return
+	}
+
+	if frameMasked {
+		for payloadIndex := range framePayload {
+			framePayload[payloadIndex] ^= frameMaskKey[payloadIndex%4]
+		}
+	}
+
+	return
+}
+
+func (codec *CodecWebSocket) writeFrame(frameOpcode byte, framePayload []byte) (err error) {
+	var (
+		frameBuffer  = make([]byte, 0, len(framePayload)+14)
+		frameLength  = len(framePayload)
+		frameMaskKey [4]byte
+		frameExtend  [8]byte
+		frameMasked  byte
+	)
+
+	if codec.client {
+		frameMasked = 0x80
+	}
+
+	frameBuffer = append(frameBuffer, 0x80|frameOpcode)
+
+	switch {
+	case frameLength < 126:
+		frameBuffer = append(frameBuffer, frameMasked|byte(frameLength))
+	case frameLength <= 0xffff:
+		binary.BigEndian.PutUint16(frameExtend[:2], uint16(frameLength))
+		frameBuffer = append(frameBuffer, frameMasked|126)
+		frameBuffer = append(frameBuffer, frameExtend[:2]...)
+	default:
+		binary.BigEndian.PutUint64(frameExtend[:8], uint64(frameLength))
+		frameBuffer = append(frameBuffer, frameMasked|127)
+		frameBuffer = append(frameBuffer, frameExtend[:8]...)
+	}
+
+	if codec.client {
+		_, err = rand.Read(frameMaskKey[:])
+		if err != nil {
+			return
+		}
+
+		frameBuffer = append(frameBuffer, frameMaskKey[:]...)
+
+		for payloadIndex, payloadByte := range framePayload {
+			frameBuffer = append(frameBuffer, payloadByte^frameMaskKey[payloadIndex%4])
+		}
+	} else {
+		frameBuffer = append(frameBuffer, framePayload...)
+	}
+
+	codec.writeMutex <- true
+	defer func() {
+		<-codec.writeMutex
+	}()
+
+	if codec.closeSent {
+		return io.ErrClosedPipe
+	}
+
+	if frameOpcode == webSocketOpClose {
+		codec.closeSent = true
+	}
+
+	if codec.config.pingInterval > 0 {
+		codec.conn.SetWriteDeadline(time.Now().Add(codec.config.pingInterval + codec.config.pongTimeout))
+	}
+
+	_, err = codec.conn.Write(frameBuffer)
+
+	return
+}
+
+func (codec *CodecWebSocket) writeClose(closeCode int, closeReason string) error {
+	closePayload := make([]byte, 2, 2+len(closeReason))
+	binary.BigEndian.PutUint16(closePayload, uint16(closeCode))
+	closePayload = append(closePayload, closeReason...)
+
+	return codec.writeFrame(webSocketOpClose, closePayload)
+}
+
+func (codec *CodecWebSocket) ping() {
+	pingTicker := time.NewTicker(codec.config.pingInterval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-pingTicker.C:
+			if codec.writeFrame(webSocketOpPing, nil) != nil {
+				return
+			}
+		case <-codec.closeMutex:
+			return
+		}
+	}
+}
+
+func (codec *CodecWebSocket) ReadMessage() (message json.RawMessage, err error) {
+	var (
+		frameFinal   bool
+		frameOpcode  byte
+		framePayload []byte
+		messageBegun bool
+		closeError   *WebSocketCloseError
+		ok           bool
+	)
+
+	if codec.readError != nil {
+		return nil, codec.readError
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		if _, ok = err.(*Error); ok {
+			return
+		}
+
+		closeError, ok = err.(*WebSocketCloseError)
+		if !ok {
+			closeError = &WebSocketCloseError{Code: WebSocketCloseAbnormal, Reason: err.Error()}
+			err = closeError
+		} else if closeError.Code != WebSocketCloseAbnormal {
+			codec.writeClose(closeError.Code, closeError.Reason)
+		}
+
+		message = nil
+		codec.readError = err
+	}()
+
+	for {
+		codec.readDeadline()
+
+		frameFinal, frameOpcode, framePayload, err = codec.readFrame()
+		if err != nil {
+			return
+		}
+
+		switch frameOpcode {
+		case webSocketOpPing:
+			err = codec.writeFrame(webSocketOpPong, framePayload)
+			if err != nil {
+				return
+			}
+
+			continue
+		case webSocketOpPong:
+			continue
+		case webSocketOpClose:
+			closeError = &WebSocketCloseError{Code: WebSocketCloseNoStatus}
+			if len(framePayload) >= 2 {
+				closeError.Code = int(binary.BigEndian.Uint16(framePayload[:2]))
+				closeError.Reason = string(framePayload[2:])
+			}
+
+			if closeError.Code == WebSocketCloseNoStatus {
+				codec.writeFrame(webSocketOpClose, nil)
+			} else {
+				codec.writeClose(closeError.Code, "")
+			}
+
+			return nil, closeError
+		case webSocketOpText, webSocketOpBinary:
+			if messageBegun {
+				return nil, &WebSocketCloseError{Code: WebSocketCloseProtocolError, Reason: "unexpected data frame"}
+			}
+
+			messageBegun = true
+		case webSocketOpContinuation:
+			if !messageBegun {
+				return nil, &WebSocketCloseError{Code: WebSocketCloseProtocolError, Reason: "unexpected continuation frame"}
+			}
+		default:
+			return nil, &WebSocketCloseError{Code: WebSocketCloseProtocolError, Reason: fmt.Sprintf("unknown opcode %d", frameOpcode)}
+		}
+
+		if codec.config.maxMessageSize > 0 && int64(len(message)+len(framePayload)) > codec.config.maxMessageSize {
+			return nil, &WebSocketCloseError{Code: WebSocketCloseMessageTooBig, Reason: "message too big"}
+		}
+
+		message = append(message, framePayload...)
+
+		if frameFinal {
+			return
+		}
+	}
+}
+
+func (codec *CodecWebSocket) WriteMessage(message json.RawMessage) error {
+	if codec.config.maxMessageSize > 0 && int64(len(message)) > codec.config.maxMessageSize {
+		return &WebSocketCloseError{Code: WebSocketCloseMessageTooBig, Reason: "message too big"}
+	}
+
+	return codec.writeFrame(webSocketOpText, message)
+}
+
+func (codec *CodecWebSocket) Close() error {
+	select {
+	case <-codec.closeMutex:
+		return nil
+	default:
+		close(codec.closeMutex)
+	}
+
+	codec.writeClose(WebSocketCloseNormal, "")
+
+	return codec.conn.Close()
+}
+
+func newCodecWebSocket(conn net.Conn, reader *bufio.Reader, client bool, config *webSocketConfig) *CodecWebSocket {
+	codec := &CodecWebSocket{
+		conn:   conn,
+		reader: reader,
+		client: client,
+		config: config,
+
+		writeMutex: make(chan interface{}, 1),
+		closeMutex: make(chan interface{}),
+	}
+
+	if config.pingInterval > 0 {
+		go codec.ping()
+	}
+
+	return codec
+}
+
+func webSocketAccept(key string) string {
+	acceptHash := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(acceptHash[:])
+}
+
+func webSocketHeaderContains(header http.Header, name string, value string) bool {
+	for _, headerValue := range header[http.CanonicalHeaderKey(name)] {
+		for _, headerToken := range strings.Split(headerValue, ",") {
+			if strings.EqualFold(strings.TrimSpace(headerToken), value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+//--------------------------------------------------------------------------------//
+// SERVER WEBSOCKET
+//--------------------------------------------------------------------------------//
+
+type webSocketRequestContext struct {
+	context.Context
+}
+
+func (requestContext webSocketRequestContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (requestContext webSocketRequestContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (requestContext webSocketRequestContext) Err() error {
+	return nil
+}
+
+type serverWebSocketHandler struct {
+	server *Server
+	config *webSocketConfig
+}
+
+func (handler *serverWebSocketHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var (
+		hijacker   http.Hijacker
+		conn       net.Conn
+		connBuffer *bufio.ReadWriter
+		key        = r.Header.Get("Sec-WebSocket-Key")
+		ok         bool
+		err        error
+	)
+
+	if r.Method != "GET" || !webSocketHeaderContains(r.Header, "Connection", "upgrade") || !webSocketHeaderContains(r.Header, "Upgrade", "websocket") {
+		http.Error(rw, "websocket upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		rw.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(rw, "unsupported websocket version", http.StatusUpgradeRequired)
+		return
+	}
+
+	if key == "" {
+		http.Error(rw, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return
+	}
+
+	if handler.config.checkOrigin != nil && !handler.config.checkOrigin(r) {
+		http.Error(rw, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	hijacker, ok = rw.(http.Hijacker)
+	if !ok {
+		http.Error(rw, "websocket upgrade is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	conn, connBuffer, err = hijacker.Hijack()
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", webSocketAccept(key))
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	handler.server.ServeCodec(webSocketRequestContext{r.Context()}, newCodecWebSocket(conn, connBuffer.Reader, false, handler.config))
+}
+
+func (server *Server) WebSocketHandler(optionArray ...WebSocketOption) http.Handler {
+	return &serverWebSocketHandler{
+		server: server,
+		config: newWebSocketConfig(optionArray),
+	}
+}
+
+func (server *Server) ServeWebSocket(rw http.ResponseWriter, r *http.Request) {
+	server.WebSocketHandler().ServeHTTP(rw, r)
+}
+
+//--------------------------------------------------------------------------------//
+// CLIENT TRANSPORT WEBSOCKET
+//--------------------------------------------------------------------------------//
+
+func DialCodecWebSocket(endpoint string, optionArray ...WebSocketOption) (codec *CodecWebSocket, err error) {
+	var (
+		config       = newWebSocketConfig(optionArray)
+		endpointUrl  *url.URL
+		endpointHost string
+		endpointTLS  bool
+		conn         net.Conn
+		connReader   *bufio.Reader
+		keyBuffer    [16]byte
+		key          string
+		httpRequest  *http.Request
+		httpResponse *http.Response
+	)
+
+	endpointUrl, err = url.Parse(endpoint)
+	if err != nil {
+		return
+	}
+
+	switch endpointUrl.Scheme {
+	case "ws", "http":
+		endpointUrl.Scheme = "http"
+	case "wss", "https":
+		endpointUrl.Scheme = "https"
+		endpointTLS = true
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %q", endpointUrl.Scheme)
+	}
+
+	endpointHost = endpointUrl.Host
+	if endpointUrl.Port() == "" {
+		if endpointTLS {
+			endpointHost = net.JoinHostPort(endpointUrl.Hostname(), "443")
+		} else {
+			endpointHost = net.JoinHostPort(endpointUrl.Hostname(), "80")
+		}
+	}
+
+	if endpointTLS {
+		tlsConfig := config.tlsConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: endpointUrl.Hostname()}
+		}
+
+		conn, err = tls.Dial("tcp", endpointHost, tlsConfig)
+	} else {
+		conn, err = net.Dial("tcp", endpointHost)
+	}
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			conn.Close()
+		}
+	}()
+
+	_, err = rand.Read(keyBuffer[:])
+	if err != nil {
+		return
+	}
+
+	key = base64.StdEncoding.EncodeToString(keyBuffer[:])
+
+	httpRequest, err = http.NewRequest("GET", endpointUrl.String(), nil)
+	if err != nil {
+		return
+	}
+
+	for headerName, headerValueArray := range config.header {
+		httpRequest.Header[headerName] = headerValueArray
+	}
+
+	httpRequest.Header.Set("Upgrade", "websocket")
+	httpRequest.Header.Set("Connection", "Upgrade")
+	httpRequest.Header.Set("Sec-WebSocket-Key", key)
+	httpRequest.Header.Set("Sec-WebSocket-Version", "13")
+
+	err = httpRequest.Write(conn)
+	if err != nil {
+		return
+	}
+
+	connReader = bufio.NewReader(conn)
+
+	httpResponse, err = http.ReadResponse(connReader, httpRequest)
+	if err != nil {
+		return
+	}
+
+	if httpResponse.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("websocket: handshake failed with status %s", httpResponse.Status)
+	}
+
+	if !webSocketHeaderContains(httpResponse.Header, "Upgrade", "websocket") || httpResponse.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		return nil, fmt.Errorf("websocket: invalid handshake response")
+	}
+
+	codec = newCodecWebSocket(conn, connReader, true, config)
+
+	return
+}
+
+func DialClientTransportWebSocket(endpoint string, optionArray ...WebSocketOption) (clientTransport *Conn, err error) {
+	var codec *CodecWebSocket
+
+	codec, err = DialCodecWebSocket(endpoint, optionArray...)
+	if err != nil {
+		return
+	}
+
+	clientTransport = NewClientTransportCodec(codec)
+
+	return
+}
+
+//--------------------------------------------------------------------------------//
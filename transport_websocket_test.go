@@ -0,0 +1,186 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testWebSocketUserKey struct{}
+
+func testWebSocketServer(t *testing.T, optionArray ...WebSocketOption) *httptest.Server {
+	t.Helper()
+
+	server := NewServer()
+
+	server.HandleFunc("echo", func(request interface{}) (interface{}, error) {
+		return request, nil
+	}, "", "")
+
+	server.HandleContextFunc("user", func(ctx context.Context, request interface{}) (interface{}, error) {
+		user, _ := ctx.Value(testWebSocketUserKey{}).(string)
+		return user, nil
+	}, nil, "")
+
+	webSocketHandler := server.WebSocketHandler(optionArray...)
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		webSocketHandler.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), testWebSocketUserKey{}, "alice")))
+	}))
+}
+
+func testWebSocketDial(t *testing.T, httpServer *httptest.Server) *CodecWebSocket {
+	t.Helper()
+
+	codec, err := DialCodecWebSocket("ws" + strings.TrimPrefix(httpServer.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codec.conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return codec
+}
+
+func testWebSocketFrame(final bool, opcode byte, payload []byte, masked bool) []byte {
+	var (
+		frame   = []byte{opcode, byte(len(payload))}
+		maskKey = []byte{1, 2, 3, 4}
+	)
+
+	if final {
+		frame[0] |= 0x80
+	}
+
+	if len(payload) >= 126 {
+		frame[1] = 126
+		frame = append(frame, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+	}
+
+	if !masked {
+		return append(frame, payload...)
+	}
+
+	frame[1] |= 0x80
+	frame = append(frame, maskKey...)
+
+	for payloadIndex, payloadByte := range payload {
+		frame = append(frame, payloadByte^maskKey[payloadIndex%4])
+	}
+
+	return frame
+}
+
+func testWebSocketExpectClose(t *testing.T, codec *CodecWebSocket, closeCode int) {
+	t.Helper()
+
+	for {
+		_, frameOpcode, framePayload, err := codec.readFrame()
+		if err != nil {
+			t.Fatalf("expected close %d, got %v", closeCode, err)
+		}
+
+		if frameOpcode != webSocketOpClose {
+			continue
+		}
+
+		if len(framePayload) < 2 || int(binary.BigEndian.Uint16(framePayload)) != closeCode {
+			t.Fatalf("expected close %d, got %v", closeCode, framePayload)
+		}
+
+		return
+	}
+}
+
+func TestWebSocketCall(t *testing.T) {
+	httpServer := testWebSocketServer(t)
+	defer httpServer.Close()
+
+	clientTransport, err := DialClientTransportWebSocket("ws" + strings.TrimPrefix(httpServer.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer clientTransport.Close()
+
+	var result string
+
+	if errorUnit := clientTransport.Client().Call("echo", &result, strings.Repeat("x", 70000)); errorUnit != nil || len(result) != 70000 {
+		t.Fatalf("echo: %v, %d bytes", errorUnit, len(result))
+	}
+
+	if errorUnit := clientTransport.Client().Call("user", &result); errorUnit != nil || result != "alice" {
+		t.Fatalf("user: expected request context values, got %q, %v", result, errorUnit)
+	}
+}
+
+func TestWebSocketFragments(t *testing.T) {
+	httpServer := testWebSocketServer(t)
+	defer httpServer.Close()
+
+	codec := testWebSocketDial(t, httpServer)
+	defer codec.conn.Close()
+
+	message := []byte(`{"jsonrpc":"2.0","id":1,"method":"echo","params":"fragmented"}`)
+
+	codec.conn.Write(testWebSocketFrame(false, webSocketOpText, message[:10], true))
+	codec.conn.Write(testWebSocketFrame(true, webSocketOpPing, []byte("ping"), true))
+	codec.conn.Write(testWebSocketFrame(false, webSocketOpContinuation, message[10:30], true))
+	codec.conn.Write(testWebSocketFrame(true, webSocketOpContinuation, message[30:], true))
+
+	_, frameOpcode, framePayload, err := codec.readFrame()
+	if err != nil || frameOpcode != webSocketOpPong || string(framePayload) != "ping" {
+		t.Fatalf("expected pong, got %d %q %v", frameOpcode, framePayload, err)
+	}
+
+	response, err := codec.ReadMessage()
+	if err != nil || !strings.Contains(string(response), `"result":"fragmented"`) {
+		t.Fatalf("expected echo response, got %s %v", response, err)
+	}
+}
+
+func TestWebSocketClose(t *testing.T) {
+	httpServer := testWebSocketServer(t, WithWebSocketMaxMessageSize(64))
+	defer httpServer.Close()
+
+	testArray := []struct {
+		name      string
+		frame     []byte
+		closeCode int
+	}{
+		{"normal close", testWebSocketFrame(true, webSocketOpClose, []byte{0x03, 0xe8}, true), WebSocketCloseNormal},
+		{"unmasked frame", testWebSocketFrame(true, webSocketOpText, []byte(`{}`), false), WebSocketCloseProtocolError},
+		{"reserved bits", append([]byte{0xc0 | webSocketOpText}, testWebSocketFrame(true, webSocketOpText, []byte(`{}`), true)[1:]...), WebSocketCloseProtocolError},
+		{"fragmented control frame", testWebSocketFrame(false, webSocketOpPing, nil, true), WebSocketCloseProtocolError},
+		{"long control frame", testWebSocketFrame(true, webSocketOpPing, make([]byte, 126), true), WebSocketCloseProtocolError},
+		{"unexpected continuation", testWebSocketFrame(true, webSocketOpContinuation, []byte(`{}`), true), WebSocketCloseProtocolError},
+		{"unknown opcode", testWebSocketFrame(true, 0x3, nil, true), WebSocketCloseProtocolError},
+		{"message too big", testWebSocketFrame(true, webSocketOpText, make([]byte, 65), true), WebSocketCloseMessageTooBig},
+	}
+
+	for _, test := range testArray {
+		t.Run(test.name, func(t *testing.T) {
+			codec := testWebSocketDial(t, httpServer)
+			defer codec.conn.Close()
+
+			codec.conn.Write(test.frame)
+
+			testWebSocketExpectClose(t, codec, test.closeCode)
+		})
+	}
+
+	t.Run("fragments too big", func(t *testing.T) {
+		codec := testWebSocketDial(t, httpServer)
+		defer codec.conn.Close()
+
+		codec.conn.Write(testWebSocketFrame(false, webSocketOpText, make([]byte, 40), true))
+		codec.conn.Write(testWebSocketFrame(true, webSocketOpContinuation, make([]byte, 40), true))
+
+		testWebSocketExpectClose(t, codec, WebSocketCloseMessageTooBig)
+	})
+}
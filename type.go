@@ -1,9 +1,15 @@
 package jsonrpc2
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
 )
 
 //--------------------------------------------------------------------------------//
@@ -25,7 +31,67 @@ func (err *Error) Error() string {
 }
 
 func (err *Error) Response() string {
-	return fmt.Sprintf(`{"jsonrpc": "2.0", "id": null, "error": {"code": %d, "message": "%s", "data": %s}}`, err.Code, err.Message, string(err.Data))
+	responseJson, errMarshal := json.Marshal(&ResponseUnit{JsonRPC: "2.0", Error: err})
+	if errMarshal != nil {
+		return fmt.Sprintf(`{"jsonrpc": "2.0", "id": null, "error": {"code": %d, "message": %q}}`, err.Code, err.Message)
+	}
+
+	return string(responseJson)
+}
+
+//--------------------------------------------------------------------------------//
+// ID
+//--------------------------------------------------------------------------------//
+
+func decodeID(input json.RawMessage) (id interface{}, err error) {
+	if len(input) == 0 {
+		return
+	}
+
+	idDecoder := json.NewDecoder(bytes.NewReader(input))
+	idDecoder.UseNumber()
+
+	err = idDecoder.Decode(&id)
+
+	return
+}
+
+func idKey(id interface{}) string {
+	if idNumber, ok := id.(json.Number); ok {
+		if idInt, err := idNumber.Int64(); err == nil {
+			return strconv.FormatInt(idInt, 10)
+		}
+
+		if idFloat, err := idNumber.Float64(); err == nil && idFloat == math.Trunc(idFloat) && math.Abs(idFloat) < 1<<53 {
+			return strconv.FormatInt(int64(idFloat), 10)
+		}
+
+		return idNumber.String()
+	}
+
+	idJson, err := json.Marshal(id)
+	if err != nil {
+		return fmt.Sprint(id)
+	}
+
+	return string(idJson)
+}
+
+func validID(id interface{}) bool {
+	switch idType := id.(type) {
+	case nil, string:
+		return true
+	case json.Number:
+		return !strings.ContainsAny(idType.String(), ".eE")
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float32:
+		return float64(idType) == math.Trunc(float64(idType))
+	case float64:
+		return idType == math.Trunc(idType)
+	}
+
+	return false
 }
 
 //--------------------------------------------------------------------------------//
@@ -42,6 +108,77 @@ type RequestUnit struct {
 	ID      interface{}     `json:"id,omitempty"`
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
+
+	invalid *Error
+}
+
+func newRequestUnit(input []byte) (requestUnit *RequestUnit) {
+	requestUnit = &RequestUnit{}
+
+	input = bytes.TrimSpace(input)
+	if len(input) == 0 || input[0] != '{' {
+		requestUnit.invalid = NewErrorInvalidRequest("request must be an object")
+		return
+	}
+
+	if err := json.Unmarshal(input, requestUnit); err != nil {
+		requestUnit = &RequestUnit{invalid: NewErrorInvalidRequest(err.Error())}
+	}
+
+	return
+}
+
+func (requestUnit *RequestUnit) hasID() bool {
+	return requestUnit.ID != nil && requestUnit.ID != false && requestUnit.ID != true
+}
+
+func (requestUnit *RequestUnit) UnmarshalJSON(input []byte) (err error) {
+	type requestUnitJson RequestUnit
+
+	var requestUnitRaw struct {
+		*requestUnitJson
+		ID json.RawMessage `json:"id,omitempty"`
+	}
+
+	requestUnitRaw.requestUnitJson = (*requestUnitJson)(requestUnit)
+
+	err = json.Unmarshal(input, &requestUnitRaw)
+	if err != nil {
+		return
+	}
+
+	requestUnit.ID, err = decodeID(requestUnitRaw.ID)
+
+	return
+}
+
+func (requestUnit *RequestUnit) Validate() *Error {
+	if requestUnit.invalid != nil {
+		return requestUnit.invalid
+	}
+
+	if requestUnit.JsonRPC != "2.0" {
+		return NewErrorInvalidRequest(`member "jsonrpc" must be exactly "2.0"`)
+	}
+
+	if !validID(requestUnit.ID) {
+		return NewErrorInvalidRequest(`member "id" must be a string, an integer or null`)
+	}
+
+	if requestUnit.Method == "" {
+		return NewErrorInvalidRequest(`member "method" is missing or empty`)
+	}
+
+	if requestUnit.Params != nil {
+		switch paramsJson := bytes.TrimSpace(requestUnit.Params); {
+		case len(paramsJson) == 0:
+			return NewErrorInvalidRequest(`member "params" is empty`)
+		case paramsJson[0] != '{' && paramsJson[0] != '[':
+			return NewErrorInvalidRequest(`member "params" must be an object or an array`)
+		}
+	}
+
+	return nil
 }
 
 func (requestUnit RequestUnit) GetRequestByte() (output []byte, err error) {
@@ -79,27 +216,34 @@ func (requestSlice RequestSlice) GetMap(extRequestSlice *RequestSlice) (requestM
 }
 
 func (requestSlice RequestSlice) MarshalJSON() ([]byte, error) {
-	if len(requestSlice) == 1 {
-		return json.Marshal(requestSlice[0])
-	}
-
 	return json.Marshal([]*RequestUnit(requestSlice))
 }
 
 func (requestSlice *RequestSlice) UnmarshalJSON(input []byte) (err error) {
 	var (
-		requestUnit  RequestUnit
 		requestArray []*RequestUnit
+		elementArray []json.RawMessage
 	)
 
-	err = json.Unmarshal(input, &requestUnit)
-	if err == nil {
-		requestArray = []*RequestUnit{&requestUnit}
-	} else {
-		err = json.Unmarshal(input, &requestArray)
-		if err != nil {
-			return
-		}
+	if !json.Valid(input) {
+		return json.Unmarshal(input, &elementArray)
+	}
+
+	if !isBatch(input) {
+		*requestSlice = RequestSlice{newRequestUnit(input)}
+
+		return
+	}
+
+	err = json.Unmarshal(input, &elementArray)
+	if err != nil {
+		return
+	}
+
+	requestArray = make([]*RequestUnit, 0, len(elementArray))
+
+	for _, element := range elementArray {
+		requestArray = append(requestArray, newRequestUnit(element))
 	}
 
 	*requestSlice = requestArray
@@ -186,6 +330,96 @@ func NewRequestSlice(inputInterace interface{}) (requestSlice RequestSlice, err
 	return
 }
 
+//--------------------------------------------------------------------------------//
+// REQUEST MESSAGE
+//--------------------------------------------------------------------------------//
+
+type requestBatchKey struct{}
+
+func WithRequestBatch(ctx context.Context, batch bool) context.Context {
+	return context.WithValue(ctx, requestBatchKey{}, batch)
+}
+
+func requestBatchFromContext(ctx context.Context, requestSlice RequestSlice) bool {
+	if ctx != nil {
+		if batch, ok := ctx.Value(requestBatchKey{}).(bool); ok {
+			return batch || len(requestSlice) != 1
+		}
+	}
+
+	return len(requestSlice) != 1
+}
+
+func isBatch(input []byte) bool {
+	input = bytes.TrimLeft(input, " \t\r\n")
+
+	return len(input) > 0 && input[0] == '['
+}
+
+type RequestMessage struct {
+	RequestSlice RequestSlice
+	Batch        bool
+}
+
+func (requestMessage RequestMessage) MarshalJSON() ([]byte, error) {
+	if requestMessage.Batch || len(requestMessage.RequestSlice) != 1 {
+		return json.Marshal([]*RequestUnit(requestMessage.RequestSlice))
+	}
+
+	return json.Marshal(requestMessage.RequestSlice[0])
+}
+
+func (requestMessage *RequestMessage) UnmarshalJSON(input []byte) (err error) {
+	err = requestMessage.RequestSlice.UnmarshalJSON(input)
+	if err != nil {
+		return
+	}
+
+	requestMessage.Batch = isBatch(input)
+
+	return
+}
+
+func (requestMessage RequestMessage) GetRequestByte() (output []byte, err error) {
+	return json.Marshal(requestMessage)
+}
+
+func (requestMessage *RequestMessage) SetRequestByte(input []byte) (err error) {
+	return json.Unmarshal(input, requestMessage)
+}
+
+func NewRequestMessage(inputInterace interface{}) (requestMessage RequestMessage, err error) {
+	var input []byte
+
+	switch inputType := inputInterace.(type) {
+	case nil:
+		requestMessage.RequestSlice = RequestSlice{}
+		return
+	case []byte:
+		input = inputType
+	case io.ReadCloser:
+		input, err = ioutil.ReadAll(inputType)
+	default:
+		err = fmt.Errorf("builder request detect unsupported type '%T'", inputType)
+	}
+
+	if err != nil {
+		return
+	}
+
+	if len(bytes.TrimSpace(input)) == 0 {
+		requestMessage.RequestSlice = RequestSlice{}
+		return
+	}
+
+	err = json.Unmarshal(input, &requestMessage)
+	if err != nil {
+		requestMessage = RequestMessage{}
+	}
+
+	return
+}
+
 //--------------------------------------------------------------------------------//
 // RESPONSE
 //--------------------------------------------------------------------------------//
@@ -202,6 +436,26 @@ type ResponseUnit struct {
 	Error   *Error          `json:"error,omitempty"`
 }
 
+func (responseUnit *ResponseUnit) UnmarshalJSON(input []byte) (err error) {
+	type responseUnitJson ResponseUnit
+
+	var responseUnitRaw struct {
+		*responseUnitJson
+		ID json.RawMessage `json:"id"`
+	}
+
+	responseUnitRaw.responseUnitJson = (*responseUnitJson)(responseUnit)
+
+	err = json.Unmarshal(input, &responseUnitRaw)
+	if err != nil {
+		return
+	}
+
+	responseUnit.ID, err = decodeID(responseUnitRaw.ID)
+
+	return
+}
+
 func (responseUnit ResponseUnit) GetResponseByte() (output []byte, err error) {
 	return json.Marshal(responseUnit)
 }
@@ -237,10 +491,6 @@ func (responseSlice ResponseSlice) GetMap(extResponseSlice *ResponseSlice) (resp
 }
 
 func (responseSlice ResponseSlice) MarshalJSON() ([]byte, error) {
-	if len(responseSlice) == 1 {
-		return json.Marshal(responseSlice[0])
-	}
-
 	return json.Marshal([]*ResponseUnit(responseSlice))
 }
 
@@ -345,3 +595,39 @@ func NewResponseSlice(inputInterace interface{}) (responseSlice ResponseSlice, e
 }
 
 //--------------------------------------------------------------------------------//
+// RESPONSE MESSAGE
+//--------------------------------------------------------------------------------//
+
+type ResponseMessage struct {
+	ResponseSlice ResponseSlice
+	Batch         bool
+}
+
+func (responseMessage ResponseMessage) MarshalJSON() ([]byte, error) {
+	if responseMessage.Batch || len(responseMessage.ResponseSlice) != 1 {
+		return json.Marshal([]*ResponseUnit(responseMessage.ResponseSlice))
+	}
+
+	return json.Marshal(responseMessage.ResponseSlice[0])
+}
+
+func (responseMessage *ResponseMessage) UnmarshalJSON(input []byte) (err error) {
+	err = responseMessage.ResponseSlice.UnmarshalJSON(input)
+	if err != nil {
+		return
+	}
+
+	responseMessage.Batch = isBatch(input)
+
+	return
+}
+
+func (responseMessage ResponseMessage) GetResponseByte() (output []byte, err error) {
+	return json.Marshal(responseMessage)
+}
+
+func (responseMessage *ResponseMessage) SetResponseByte(input []byte) (err error) {
+	return json.Unmarshal(input, responseMessage)
+}
+
+//--------------------------------------------------------------------------------//